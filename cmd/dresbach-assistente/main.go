@@ -5,11 +5,21 @@ import (
 	"context"
 	"log"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/dresbach/dresbach-assistente/pkg/config"
+	"github.com/dresbach/dresbach-assistente/pkg/credentials"
 	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/domain"
+	"github.com/dresbach/dresbach-assistente/pkg/gemini"
+	"github.com/dresbach/dresbach-assistente/pkg/intent"
+	"github.com/dresbach/dresbach-assistente/pkg/products"
 	"github.com/dresbach/dresbach-assistente/pkg/state"
 	"github.com/dresbach/dresbach-assistente/pkg/stripe"
+	"github.com/dresbach/dresbach-assistente/pkg/techops"
 	"github.com/dresbach/dresbach-assistente/pkg/whatsapp"
 	"github.com/dresbach/dresbach-assistente/pkg/whm"
 
@@ -39,32 +49,123 @@ func main() {
 	defer dbStore.Close(ctx)
 
 	// 3. Inicializa os clientes dos serviços externos
-	whmClient := whm.NewClient(cfg.WHMHost, cfg.WHMAPIToken)
-	stripeClient := stripe.NewClient(cfg.StripeKey)
+	whmClient, err := whm.NewClient(cfg.WHMHost, cfg.WHMAPIToken, whm.Options{Timeout: cfg.WHMTimeout, CABundle: cfg.WHMCABundle})
+	if err != nil {
+		log.Fatalf("Erro ao configurar o cliente do WHM: %v", err)
+	}
+	stripeClient := stripe.NewClient(cfg.StripeKey, cfg.CheckoutSuccessURL, cfg.CheckoutCancelURL)
+	stripeClient.PixExpiration = cfg.PixExpiration
+	stripeClient.CheckoutExpiration = cfg.CheckoutExpiration
 	whatsappClient := whatsapp.NewClient(cfg.WhatsAppToken, cfg.WhatsAppBusinessAccID, cfg.WhatsAppPhoneNumberID)
 
-	// 4. Inicializa o StateManager, injetando todas as dependências
-	stateManager := state.NewManager(dbStore, whmClient, stripeClient, whatsappClient)
+	// 4. Carrega o catálogo de produtos (Stripe, com fallback para as exportações CSV)
+	catalog := products.NewCatalog(cfg.WHMPackages,
+		products.SourceFunc(stripeClient.LoadProducts),
+		products.CSVSource{ProductPaths: cfg.CatalogCSVPaths, PricesPath: cfg.CatalogPricesCSVPath},
+	)
+	if err := catalog.Reload(); err != nil {
+		log.Fatalf("Erro ao carregar o catálogo de produtos: %v", err)
+	}
+	go reloadCatalog(catalog, cfg.CatalogRefreshInterval)
+
+	// 5. Inicializa o StateManager, injetando todas as dependências
+	credentialService, err := credentials.NewService(dbStore, cfg.CredentialsKey, cfg.PublicBaseURL, cfg.CredentialsLinkTTL)
+	if err != nil {
+		log.Fatalf("Erro ao inicializar o serviço de credenciais: %v", err)
+	}
+	domainChecker := domain.NewChecker(cfg.RDAPTimeout)
+	domainChecker.RegistroBRURL = cfg.RDAPRegistroBRURL
+	domainChecker.BootstrapURL = cfg.RDAPBootstrapURL
+	ownershipVerifier := domain.NewVerifier(cfg.DNSResolver, cfg.DNSTimeout)
+	// Os classificadores por IA são opcionais; sem eles o menu e o Tech Ops usam palavras-chave.
+	var classifier intent.Classifier
+	var leadClassifier techops.Classifier
+	if cfg.GeminiAPIKey != "" {
+		geminiClient, err := gemini.NewClient(cfg.GeminiAPIKey, cfg.GeminiModel)
+		if err != nil {
+			log.Fatalf("Erro ao inicializar o cliente do Gemini: %v", err)
+		}
+		defer geminiClient.Close()
+		classifier = geminiClient
+		leadClassifier = geminiClient
+	}
+	stateManager := state.NewManager(state.Deps{
+		Store:        dbStore,
+		WHM:          whmClient,
+		Stripe:       stripeClient,
+		WhatsApp:     whatsappClient,
+		Catalog:      catalog,
+		Credentials:  credentialService,
+		Domains:      domainChecker,
+		Ownership:    ownershipVerifier,
+		Nameservers:  cfg.Nameservers,
+		Intents:      classifier,
+		IntentPolicy: state.IntentPolicy{Accept: cfg.IntentAcceptConfidence, Confirm: cfg.IntentConfirmConfidence},
+		Leads:        leadClassifier,
+	})
+	go stateManager.RunProvisioningJobs(ctx, cfg.ProvisioningRetryInterval)
+	go stateManager.RunPaymentReminders(ctx, state.ReminderPolicy{
+		Interval:     cfg.PaymentReminderInterval,
+		Schedule:     cfg.PaymentReminders,
+		AbandonAfter: cfg.PaymentAbandonAfter,
+	})
+	go stateManager.RunOwnershipChecks(ctx, state.OwnershipPolicy{
+		Interval: cfg.DomainVerificationInterval,
+		Timeout:  cfg.DomainVerificationTimeout,
+	})
+	go stateManager.RunDomainTransfers(ctx, state.TransferPolicy{
+		Interval:      cfg.DomainTransferInterval,
+		ReminderAfter: cfg.DomainTransferReminderAfter,
+	})
 
 	log.Println("Iniciando o servidor Dresbach Assistente na porta 8080...")
 
-	// 5. Cria os Handlers HTTP
+	// 6. Cria os Handlers HTTP
 	whatsappWebhookHandler := &whatsapp.WebhookHandler{
 		WhatsAppClient: whatsappClient,
 		StateManager:   stateManager,
 	}
 
-	// O WebhookHandler do Stripe agora só precisa do Provisioner
+	// O WebhookHandler do Stripe depende apenas de interfaces implementadas pelo StateManager
 	stripeWebhookHandler := &stripe.WebhookHandler{
+		Client:              stripeClient,
 		Provisioner:         stateManager, // stateManager implementa a interface AccountProvisioner
+		Customers:           stateManager, // e também a interface CustomerLinker
+		Subscriptions:       stateManager, // e a SubscriptionManager
+		Payments:            stateManager, // e a PaymentNotifier
+		Retries:             stateManager, // e a ProvisioningQueue
+		Reversals:           stateManager, // e a ReversalHandler
+		Events:              dbStore,      // O registro de eventos fica no MongoDB
 		StripeWebhookSecret: cfg.StripeWebhookSecret,
 	}
 
-	// 6. Registra os handlers e inicia o servidor
+	// 7. Registra os handlers e inicia o servidor
 	http.Handle("/whatsapp-webhook", whatsappWebhookHandler)
 	http.Handle("/stripe-webhook", stripeWebhookHandler)
+	http.Handle(credentials.PathPrefix, credentialService)
 
 	if err := http.ListenAndServe(":8080", nil); err != nil {
 		log.Fatalf("Erro ao iniciar o servidor: %v", err)
 	}
 }
+
+// reloadCatalog recarrega o catálogo periodicamente e sempre que o processo
+// recebe SIGHUP, permitindo atualizar produtos e preços sem um novo deploy.
+func reloadCatalog(catalog *products.Catalog, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-hup:
+			log.Println("SIGHUP recebido, recarregando o catálogo de produtos...")
+		}
+		if err := catalog.Reload(); err != nil {
+			log.Printf("ERRO: %v", err)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/kelseyhightower/envconfig"
 )
@@ -18,12 +19,63 @@ type Config struct {
 	MongoURI string `envconfig:"MONGO_URI" required:"true"`
 
 	// WHM
-	WHMHost     string `envconfig:"WHM_HOST" required:"true"`
-	WHMAPIToken string `envconfig:"WHM_API_TOKEN" required:"true"`
+	WHMHost     string        `envconfig:"WHM_HOST" required:"true"` // Aceita "servidor", "https://servidor" ou "servidor:porta"
+	WHMAPIToken string        `envconfig:"WHM_API_TOKEN" required:"true"`
+	WHMTimeout  time.Duration `envconfig:"WHM_TIMEOUT" default:"30s"`
+	WHMCABundle string        `envconfig:"WHM_CA_BUNDLE"` // Arquivo PEM para servidores com certificado próprio
+	// WHMPackages mapeia o ID do produto na Stripe para o pacote do WHM (formato "prod_x:Pacote,prod_y:Pacote").
+	WHMPackages map[string]string `envconfig:"WHM_PACKAGES" default:"prod_TlHmZ8KTx0pe0y:Dresbach-Start,prod_TlHnHEozuwnuQZ:Dresbach-Plus,prod_TlHnyj6Y4xiHMp:Dresbach-Pro,prod_Tl2cU7tNegEibw:Dresbach-WP"`
 
 	// Stripe
 	StripeKey           string `envconfig:"STRIPE_KEY" required:"true"`
 	StripeWebhookSecret string `envconfig:"STRIPE_WEBHOOK_SECRET" required:"true"`
+	CheckoutSuccessURL  string `envconfig:"CHECKOUT_SUCCESS_URL" default:"https://dresbachhosting.com.br/sucesso"`
+	CheckoutCancelURL   string `envconfig:"CHECKOUT_CANCEL_URL" default:"https://dresbachhosting.com.br/cancelamento"`
+
+	// Prazo para pagamento das cobranças Pix
+	PixExpiration time.Duration `envconfig:"PIX_EXPIRATION" default:"30m"`
+
+	// Validade dos links de checkout e lembretes de pagamento pendente
+	CheckoutExpiration      time.Duration   `envconfig:"CHECKOUT_EXPIRATION" default:"24h"`
+	PaymentReminders        []time.Duration `envconfig:"PAYMENT_REMINDERS" default:"1h,6h,20h"`
+	PaymentReminderInterval time.Duration   `envconfig:"PAYMENT_REMINDER_INTERVAL" default:"5m"`
+	PaymentAbandonAfter     time.Duration   `envconfig:"PAYMENT_ABANDON_AFTER" default:"48h"`
+
+	// Catálogo de produtos: a Stripe é a origem principal e as exportações CSV são o fallback.
+	CatalogCSVPaths        []string      `envconfig:"CATALOG_CSV_PATHS" default:"docs/products (1).csv,docs/products techops.csv"`
+	CatalogPricesCSVPath   string        `envconfig:"CATALOG_PRICES_CSV_PATH"`
+	CatalogRefreshInterval time.Duration `envconfig:"CATALOG_REFRESH_INTERVAL" default:"1h"`
+
+	// Links de uso único para entrega das credenciais das contas cPanel
+	PublicBaseURL      string        `envconfig:"PUBLIC_BASE_URL" default:"https://assistente.dresbachhosting.com.br"`
+	CredentialsKey     string        `envconfig:"CREDENTIALS_KEY" required:"true"` // Chave AES-256 em base64
+	CredentialsLinkTTL time.Duration `envconfig:"CREDENTIALS_LINK_TTL" default:"24h"`
+
+	// Consulta de domínios via RDAP (Registro.br para o .br e bootstrap da IANA para as demais extensões)
+	RDAPRegistroBRURL string        `envconfig:"RDAP_REGISTRO_BR_URL" default:"https://rdap.registro.br"`
+	RDAPBootstrapURL  string        `envconfig:"RDAP_BOOTSTRAP_URL" default:"https://data.iana.org/rdap/dns.json"`
+	RDAPTimeout       time.Duration `envconfig:"RDAP_TIMEOUT" default:"10s"`
+
+	// Verificação da titularidade dos domínios por registro TXT. DNS_RESOLVER ("host:porta")
+	// consulta um servidor específico; vazio usa o resolvedor do sistema.
+	DNSResolver                string        `envconfig:"DNS_RESOLVER" default:"1.1.1.1:53"`
+	DNSTimeout                 time.Duration `envconfig:"DNS_TIMEOUT" default:"5s"`
+	DomainVerificationInterval time.Duration `envconfig:"DOMAIN_VERIFICATION_INTERVAL" default:"2m"`
+	DomainVerificationTimeout  time.Duration `envconfig:"DOMAIN_VERIFICATION_TIMEOUT" default:"24h"`
+
+	// Servidores DNS da hospedagem e acompanhamento dos domínios já registrados que passam a apontar para ela
+	Nameservers                 []string      `envconfig:"NAMESERVERS" default:"ns1.dresbachhosting.com.br,ns2.dresbachhosting.com.br"`
+	DomainTransferInterval      time.Duration `envconfig:"DOMAIN_TRANSFER_INTERVAL" default:"15m"`
+	DomainTransferReminderAfter time.Duration `envconfig:"DOMAIN_TRANSFER_REMINDER_AFTER" default:"48h"`
+
+	// Classificação do texto livre no menu principal. Sem GEMINI_API_KEY, apenas palavras-chave são usadas.
+	GeminiAPIKey            string  `envconfig:"GEMINI_API_KEY"`
+	GeminiModel             string  `envconfig:"GEMINI_MODEL" default:"gemini-1.5-flash"`
+	IntentAcceptConfidence  float64 `envconfig:"INTENT_ACCEPT_CONFIDENCE" default:"0.8"`
+	IntentConfirmConfidence float64 `envconfig:"INTENT_CONFIRM_CONFIDENCE" default:"0.5"`
+
+	// Intervalo entre as rodadas de reprocessamento dos provisionamentos que falharam
+	ProvisioningRetryInterval time.Duration `envconfig:"PROVISIONING_RETRY_INTERVAL" default:"1m"`
 }
 
 // New carrega a configuração a partir de variáveis de ambiente.
@@ -0,0 +1,93 @@
+package credentials
+
+import (
+	"html/template"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// PathPrefix é a rota em que os links de credenciais são atendidos.
+const PathPrefix = "/credenciais/"
+
+// confirmPage é exibida no GET. O dado sigiloso só é revelado no POST, para que a prévia
+// de links do WhatsApp não consuma o link antes do cliente.
+var confirmPage = template.Must(template.New("confirm").Parse(`<!DOCTYPE html>
+<html lang="pt-BR"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex"><title>Dados de acesso · Dresbach Hosting</title></head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 2em auto; padding: 0 1em;">
+{{if eq .Kind "mailbox"}}<h2>Dados de acesso do seu e-mail</h2>
+<p>Conta de e-mail <strong>{{.Username}}</strong>.</p>{{else if eq .Kind "auth_code"}}<h2>Código de transferência (EPP)</h2>
+<p>Domínio <strong>{{.Domain}}</strong>.</p>{{else}}<h2>Dados de acesso da sua hospedagem</h2>
+<p>Conta do domínio <strong>{{.Domain}}</strong>.</p>{{end}}
+<p>Este link só pode ser aberto <strong>uma vez</strong>. Anote os dados em um local seguro antes de fechar a página.</p>
+<form method="post"><button type="submit" style="font-size: 1.1em; padding: .6em 1.2em;">Ver meus dados de acesso</button></form>
+</body></html>`))
+
+var credentialsPage = template.Must(template.New("credentials").Parse(`<!DOCTYPE html>
+<html lang="pt-BR"><head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex"><title>Dados de acesso · Dresbach Hosting</title></head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 2em auto; padding: 0 1em;">
+{{if eq .Kind "auth_code"}}<h2>Código de transferência (EPP)</h2>
+<p><strong>Domínio:</strong> <code>{{.Domain}}</code></p>
+<p><strong>Código:</strong> <code>{{.Password}}</code></p>
+{{else}}{{if eq .Kind "mailbox"}}<h2>Dados de acesso do seu e-mail</h2>
+<p><strong>Webmail:</strong> <a href="https://{{.Domain}}/webmail">https://{{.Domain}}/webmail</a></p>{{else}}<h2>Dados de acesso da sua hospedagem</h2>
+<p><strong>Painel:</strong> <a href="https://{{.Domain}}/cpanel">https://{{.Domain}}/cpanel</a></p>{{end}}
+<p><strong>Usuário:</strong> <code>{{.Username}}</code></p>
+<p><strong>Senha:</strong> <code>{{.Password}}</code></p>{{end}}
+<p>Este link já foi utilizado e não funcionará novamente.{{if ne .Kind "auth_code"}} Recomendamos trocar a senha no primeiro acesso.{{end}}</p>
+</body></html>`))
+
+const invalidLinkMessage = "Este link é inválido, já foi utilizado ou expirou. Fale com a gente pelo WhatsApp para receber um novo."
+
+// ServeHTTP atende os links de credenciais: o GET pede confirmação e o POST
+// revela os dados de acesso e invalida o link.
+func (s *Service) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.URL.Path, PathPrefix)
+	if token == "" || strings.Contains(token, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+
+	switch r.Method {
+	case http.MethodGet:
+		link, err := s.store.FindCredentialLink(r.Context(), hashToken(token))
+		if err != nil {
+			log.Printf("ERRO: %v", err)
+			http.Error(w, "Erro interno. Tente novamente em instantes.", http.StatusInternalServerError)
+			return
+		}
+		if link == nil {
+			http.Error(w, invalidLinkMessage, http.StatusNotFound)
+			return
+		}
+		confirmPage.Execute(w, struct{ Domain, Username, Kind string }{link.Domain, link.Username, link.Kind})
+
+	case http.MethodPost:
+		link, err := s.store.ConsumeCredentialLink(r.Context(), hashToken(token))
+		if err != nil {
+			log.Printf("ERRO: %v", err)
+			http.Error(w, "Erro interno. Tente novamente em instantes.", http.StatusInternalServerError)
+			return
+		}
+		if link == nil {
+			http.Error(w, invalidLinkMessage, http.StatusNotFound)
+			return
+		}
+		password, err := s.Open(link.Sealed)
+		if err != nil {
+			log.Printf("ERRO: Link de credenciais do usuário %s: %v", link.UserID, err)
+			http.Error(w, invalidLinkMessage, http.StatusNotFound)
+			return
+		}
+
+		log.Printf("Link de credenciais da conta %s aberto pelo cliente.", link.Username)
+		credentialsPage.Execute(w, struct{ Domain, Username, Password, Kind string }{link.Domain, link.Username, password, link.Kind})
+
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,168 @@
+package credentials
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+)
+
+// defaultLinkTTL é a validade dos links de credenciais quando não configurada.
+const defaultLinkTTL = 24 * time.Hour
+
+// Service emite links de uso único para o cliente ver os dados de acesso da conta.
+// A senha só é gravada cifrada e é apagada assim que o link é aberto ou expira.
+type Service struct {
+	store   *database.MongoStore
+	aead    cipher.AEAD
+	baseURL string
+	ttl     time.Duration
+}
+
+// NewService cria o serviço de credenciais. key é a chave AES-256 em base64 e é
+// obrigatória: os links e os códigos EPP guardados nas sessões e nos jobs de
+// provisionamento só podem ser abertos com a mesma chave que os cifrou.
+func NewService(store *database.MongoStore, key, baseURL string, ttl time.Duration) (*Service, error) {
+	if key == "" {
+		return nil, fmt.Errorf("CREDENTIALS_KEY não definida")
+	}
+	secret, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("CREDENTIALS_KEY inválida: %w", err)
+	}
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("CREDENTIALS_KEY deve ter 32 bytes, tem %d", len(secret))
+	}
+
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar a cifra de credenciais: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar a cifra de credenciais: %w", err)
+	}
+
+	if ttl <= 0 {
+		ttl = defaultLinkTTL
+	}
+	return &Service{
+		store:   store,
+		aead:    aead,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		ttl:     ttl,
+	}, nil
+}
+
+// Tipos de link. Os links sem tipo trazem o acesso ao cPanel.
+const (
+	// KindMailbox identifica os links com a senha de uma conta de e-mail.
+	KindMailbox = "mailbox"
+	// KindAuthCode identifica os links com o código EPP de um domínio, abertos pela equipe.
+	KindAuthCode = "auth_code"
+)
+
+// authCodeLinkTTL é a validade dos links de código EPP, que aguardam a equipe na fila de atendimento.
+const authCodeLinkTTL = 7 * 24 * time.Hour
+
+// Issue grava a senha cifrada e retorna o link de uso único para o cliente.
+func (s *Service) Issue(ctx context.Context, userID, username, domain, password string) (string, time.Time, error) {
+	sealed, err := s.Seal(password)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return s.issue(ctx, "", userID, username, domain, sealed, s.ttl)
+}
+
+// IssueMailbox retorna o link de uso único com a senha da conta de e-mail informada.
+func (s *Service) IssueMailbox(ctx context.Context, userID, email, domain, password string) (string, time.Time, error) {
+	sealed, err := s.Seal(password)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return s.issue(ctx, KindMailbox, userID, email, domain, sealed, s.ttl)
+}
+
+// IssueAuthCode retorna o link de uso único com o código EPP do domínio, já cifrado
+// por Seal, para a equipe solicitar a transferência do registro.
+func (s *Service) IssueAuthCode(ctx context.Context, userID, domain string, sealed []byte) (string, time.Time, error) {
+	if _, err := s.Open(sealed); err != nil {
+		return "", time.Time{}, err
+	}
+	return s.issue(ctx, KindAuthCode, userID, domain, domain, sealed, authCodeLinkTTL)
+}
+
+func (s *Service) issue(ctx context.Context, kind, userID, username, domain string, sealed []byte, ttl time.Duration) (string, time.Time, error) {
+	token := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, token); err != nil {
+		return "", time.Time{}, fmt.Errorf("falha ao gerar o token de credenciais: %w", err)
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(token)
+
+	// Um link novo substitui os anteriores da mesma conta, que podem trazer uma
+	// senha já redefinida (ex.: quando a etapa de credenciais é repetida).
+	if err := s.store.RevokeCredentialLinks(ctx, userID, username, kind); err != nil {
+		return "", time.Time{}, err
+	}
+
+	now := time.Now()
+	link := &database.CredentialLink{
+		TokenHash: hashToken(encoded),
+		UserID:    userID,
+		Username:  username,
+		Domain:    domain,
+		Kind:      kind,
+		Sealed:    sealed,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	if err := s.store.SaveCredentialLink(ctx, link); err != nil {
+		return "", time.Time{}, err
+	}
+
+	// Aproveita a emissão para limpar links que expiraram sem uso.
+	if err := s.store.DeleteExpiredCredentialLinks(ctx); err != nil {
+		log.Printf("AVISO: %v", err)
+	}
+
+	return fmt.Sprintf("%s%s%s", s.baseURL, PathPrefix, encoded), link.ExpiresAt, nil
+}
+
+// Seal cifra um dado sigiloso (senha, código EPP) com um nonce aleatório, gravado
+// junto ao texto cifrado. O resultado só é aberto por Open com a mesma chave.
+func (s *Service) Seal(secret string) ([]byte, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("falha ao gerar o nonce de credenciais: %w", err)
+	}
+	return s.aead.Seal(nonce, nonce, []byte(secret), nil), nil
+}
+
+// Open decifra um dado gravado por Seal.
+func (s *Service) Open(sealed []byte) (string, error) {
+	size := s.aead.NonceSize()
+	if len(sealed) < size {
+		return "", fmt.Errorf("credencial cifrada inválida")
+	}
+	plain, err := s.aead.Open(nil, sealed[:size], sealed[size:], nil)
+	if err != nil {
+		return "", fmt.Errorf("falha ao decifrar a credencial: %w", err)
+	}
+	return string(plain), nil
+}
+
+// hashToken retorna o hash gravado no lugar do token do link.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,68 @@
+package credentials
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+)
+
+// passwordLength é o tamanho das senhas geradas para as contas cPanel.
+const passwordLength = 20
+
+// Conjuntos de caracteres das senhas. Caracteres ambíguos (0/O, 1/l/I) ficam de
+// fora para facilitar a digitação; os símbolos são aceitos pelo WHM sem escape.
+const (
+	lowerChars  = "abcdefghijkmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHJKLMNPQRSTUVWXYZ"
+	digitChars  = "23456789"
+	symbolChars = "!@#%^*-_=+"
+)
+
+// GeneratePassword gera uma senha aleatória forte, com ao menos uma letra
+// minúscula, uma maiúscula, um dígito e um símbolo.
+func GeneratePassword() (string, error) {
+	classes := []string{lowerChars, upperChars, digitChars, symbolChars}
+	all := lowerChars + upperChars + digitChars + symbolChars
+
+	password := make([]byte, 0, passwordLength)
+	for _, class := range classes {
+		c, err := randomChar(class)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+	for len(password) < passwordLength {
+		c, err := randomChar(all)
+		if err != nil {
+			return "", err
+		}
+		password = append(password, c)
+	}
+
+	// Embaralha para que os caracteres obrigatórios não fiquem sempre no início.
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return "", err
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+func randomChar(set string) (byte, error) {
+	i, err := randomInt(len(set))
+	if err != nil {
+		return 0, err
+	}
+	return set[i], nil
+}
+
+func randomInt(max int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(max)))
+	if err != nil {
+		return 0, fmt.Errorf("falha ao gerar número aleatório: %w", err)
+	}
+	return int(n.Int64()), nil
+}
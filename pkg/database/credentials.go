@@ -0,0 +1,84 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CredentialLink é um link de uso único para o cliente ver os dados de acesso da
+// conta. Apenas o hash do token e a senha cifrada são gravados.
+type CredentialLink struct {
+	TokenHash string    `bson:"_id"`
+	UserID    string    `bson:"user_id"`
+	Username  string    `bson:"username"`
+	Domain    string    `bson:"domain"`
+	Kind      string    `bson:"kind,omitempty"` // Vazio para a conta cPanel
+	Sealed    []byte    `bson:"sealed"`         // Senha cifrada com a chave do serviço de credenciais
+	ExpiresAt time.Time `bson:"expires_at"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// SaveCredentialLink grava um novo link de credenciais.
+func (s *MongoStore) SaveCredentialLink(ctx context.Context, link *CredentialLink) error {
+	if _, err := s.credentials.InsertOne(ctx, link); err != nil {
+		return fmt.Errorf("falha ao gravar link de credenciais no MongoDB: %w", err)
+	}
+	return nil
+}
+
+// FindCredentialLink carrega um link de credenciais ainda válido. Retorna nil se o
+// link não existir, já tiver sido usado ou estiver expirado.
+func (s *MongoStore) FindCredentialLink(ctx context.Context, tokenHash string) (*CredentialLink, error) {
+	var link CredentialLink
+	filter := bson.M{"_id": tokenHash, "expires_at": bson.M{"$gt": time.Now()}}
+
+	if err := s.credentials.FindOne(ctx, filter).Decode(&link); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("falha ao carregar link de credenciais do MongoDB: %w", err)
+	}
+	return &link, nil
+}
+
+// ConsumeCredentialLink remove e retorna um link de credenciais ainda válido,
+// garantindo que ele seja usado uma única vez. Retorna nil se o link não for válido.
+func (s *MongoStore) ConsumeCredentialLink(ctx context.Context, tokenHash string) (*CredentialLink, error) {
+	var link CredentialLink
+	filter := bson.M{"_id": tokenHash, "expires_at": bson.M{"$gt": time.Now()}}
+
+	if err := s.credentials.FindOneAndDelete(ctx, filter).Decode(&link); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("falha ao consumir link de credenciais do MongoDB: %w", err)
+	}
+	return &link, nil
+}
+
+// RevokeCredentialLinks remove os links ainda não usados de uma conta, do tipo
+// informado, para que apenas o link mais recente continue válido.
+func (s *MongoStore) RevokeCredentialLinks(ctx context.Context, userID, username, kind string) error {
+	filter := bson.M{"user_id": userID, "username": username, "kind": kind}
+	if kind == "" {
+		// Os links da conta cPanel são gravados sem o campo kind; nil também o encontra ausente.
+		filter["kind"] = nil
+	}
+	if _, err := s.credentials.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("falha ao revogar links de credenciais no MongoDB: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpiredCredentialLinks remove os links expirados que não foram usados.
+func (s *MongoStore) DeleteExpiredCredentialLinks(ctx context.Context) error {
+	filter := bson.M{"expires_at": bson.M{"$lte": time.Now()}}
+	if _, err := s.credentials.DeleteMany(ctx, filter); err != nil {
+		return fmt.Errorf("falha ao remover links de credenciais expirados do MongoDB: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Customer armazena o cadastro de um cliente de hospedagem.
+type Customer struct {
+	UserID           string `bson:"user_id"`
+	Name             string `bson:"name,omitempty"`
+	Email            string `bson:"email,omitempty"`
+	StripeCustomerID string `bson:"stripe_customer_id,omitempty"`
+	// Domain e WHMUsername identificam a conta de hospedagem provisionada.
+	Domain      string `bson:"domain,omitempty"`
+	WHMUsername string `bson:"whm_username,omitempty"`
+	WHMPackage  string `bson:"whm_package,omitempty"`
+	// SubscriptionID é a assinatura da Stripe que mantém a conta ativa.
+	SubscriptionID string `bson:"subscription_id,omitempty"`
+	AccountStatus  string `bson:"account_status,omitempty"`
+	// DiagnosticStatus acompanha o Diagnóstico Tech Ops comprado pelo cliente.
+	DiagnosticStatus string `bson:"diagnostic_status,omitempty"`
+	// FinancialEvents registra estornos e contestações dos pagamentos do cliente.
+	FinancialEvents []FinancialEvent `bson:"financial_events,omitempty"`
+}
+
+// FinancialEvent é um estorno ou uma contestação de pagamento.
+type FinancialEvent struct {
+	Kind      string    `bson:"kind"`
+	ChargeID  string    `bson:"charge_id"`
+	ProductID string    `bson:"product_id,omitempty"`
+	Amount    int64     `bson:"amount"`
+	Currency  string    `bson:"currency"`
+	Reason    string    `bson:"reason,omitempty"`
+	CreatedAt time.Time `bson:"created_at"`
+}
+
+// Situações possíveis da conta de hospedagem do cliente.
+const (
+	AccountStatusActive     = "active"
+	AccountStatusSuspended  = "suspended"
+	AccountStatusTerminated = "terminated"
+)
+
+// Situações possíveis do Diagnóstico Tech Ops.
+const (
+	DiagnosticStatusScheduled = "scheduled"
+	DiagnosticStatusCanceled  = "canceled"
+)
+
+// ErrCustomerNotFound indica que nenhum cliente corresponde à busca.
+var ErrCustomerNotFound = errors.New("cliente não encontrado")
+
+// LoadCustomer carrega o cadastro do cliente do MongoDB.
+// Se o cadastro não existir, retorna um cliente vazio com o UserID preenchido.
+func (s *MongoStore) LoadCustomer(ctx context.Context, userID string) (*Customer, error) {
+	var customer Customer
+	filter := bson.M{"user_id": userID}
+
+	err := s.customers.FindOne(ctx, filter).Decode(&customer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &Customer{UserID: userID}, nil
+		}
+		return nil, fmt.Errorf("falha ao carregar cliente do MongoDB: %w", err)
+	}
+	return &customer, nil
+}
+
+// FindCustomerBySubscription carrega o cliente vinculado a uma assinatura da Stripe.
+func (s *MongoStore) FindCustomerBySubscription(ctx context.Context, subscriptionID string) (*Customer, error) {
+	var customer Customer
+	filter := bson.M{"subscription_id": subscriptionID}
+
+	if err := s.customers.FindOne(ctx, filter).Decode(&customer); err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("falha ao buscar cliente da assinatura %s no MongoDB: %w", subscriptionID, err)
+	}
+	return &customer, nil
+}
+
+// FindCustomerByStripeID carrega o cliente vinculado a um cliente da Stripe.
+func (s *MongoStore) FindCustomerByStripeID(ctx context.Context, stripeCustomerID string) (*Customer, error) {
+	var customer Customer
+	filter := bson.M{"stripe_customer_id": stripeCustomerID}
+
+	if err := s.customers.FindOne(ctx, filter).Decode(&customer); err != nil {
+		if err == mongo.ErrNoDocuments {
+			err = ErrCustomerNotFound
+		}
+		return nil, fmt.Errorf("falha ao buscar o cliente Stripe %s no MongoDB: %w", stripeCustomerID, err)
+	}
+	return &customer, nil
+}
+
+// UpdateCustomer grava apenas os campos informados em set (ex.: "account_status"),
+// criando o cadastro se ele ainda não existir. Webhooks, provisionamento e estornos
+// atualizam o mesmo cadastro ao mesmo tempo: gravar o documento inteiro desfaria
+// as alterações dos outros.
+func (s *MongoStore) UpdateCustomer(ctx context.Context, userID string, set map[string]interface{}) error {
+	return s.updateCustomer(ctx, userID, bson.M{"$set": set})
+}
+
+// AddFinancialEvent acrescenta o evento ao histórico financeiro do cliente e grava
+// os campos informados em set na mesma operação.
+func (s *MongoStore) AddFinancialEvent(ctx context.Context, userID string, event FinancialEvent, set map[string]interface{}) error {
+	update := bson.M{"$push": bson.M{"financial_events": event}}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	return s.updateCustomer(ctx, userID, update)
+}
+
+func (s *MongoStore) updateCustomer(ctx context.Context, userID string, update bson.M) error {
+	filter := bson.M{"user_id": userID}
+	opts := options.Update().SetUpsert(true)
+
+	if _, err := s.customers.UpdateOne(ctx, filter, update, opts); err != nil {
+		return fmt.Errorf("falha ao salvar cliente no MongoDB: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// StripeEvent registra o processamento de um evento de webhook da Stripe.
+type StripeEvent struct {
+	ID         string    `bson:"_id"`
+	Type       string    `bson:"type"`
+	Status     string    `bson:"status"`
+	Attempts   int       `bson:"attempts"`
+	LastError  string    `bson:"last_error,omitempty"`
+	ReceivedAt time.Time `bson:"received_at"`
+	UpdatedAt  time.Time `bson:"updated_at"`
+}
+
+// Situações possíveis de um evento da Stripe no registro.
+const (
+	EventStatusProcessing = "processing"
+	EventStatusProcessed  = "processed"
+	EventStatusFailed     = "failed"
+)
+
+// eventProcessingTimeout é o tempo após o qual um evento preso em processamento
+// (ex.: o processo caiu no meio) pode ser reivindicado por uma nova entrega.
+const eventProcessingTimeout = 5 * time.Minute
+
+// ClaimStripeEvent reivindica o processamento de um evento da Stripe. Retorna false
+// se o evento já foi processado ou se outra entrega o está processando agora.
+func (s *MongoStore) ClaimStripeEvent(ctx context.Context, eventID, eventType string) (bool, error) {
+	now := time.Now()
+	// Só reivindica eventos novos, que falharam ou que ficaram presos em processamento.
+	filter := bson.M{
+		"_id": eventID,
+		"$or": bson.A{
+			bson.M{"status": EventStatusFailed},
+			bson.M{"status": EventStatusProcessing, "updated_at": bson.M{"$lt": now.Add(-eventProcessingTimeout)}},
+		},
+	}
+	update := bson.M{
+		"$set":         bson.M{"status": EventStatusProcessing, "updated_at": now},
+		"$inc":         bson.M{"attempts": 1},
+		"$setOnInsert": bson.M{"type": eventType, "received_at": now},
+	}
+	opts := options.Update().SetUpsert(true)
+
+	_, err := s.events.UpdateOne(ctx, filter, update, opts)
+	if err != nil {
+		// O evento existe, mas não atende ao filtro: a tentativa de inserir o mesmo _id falha.
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("falha ao registrar o evento %s da Stripe no MongoDB: %w", eventID, err)
+	}
+	return true, nil
+}
+
+// FinishStripeEvent registra o resultado do processamento de um evento da Stripe.
+// Eventos com falha podem ser reivindicados novamente quando a Stripe os reenviar.
+func (s *MongoStore) FinishStripeEvent(ctx context.Context, eventID string, procErr error) error {
+	set := bson.M{"status": EventStatusProcessed, "last_error": "", "updated_at": time.Now()}
+	if procErr != nil {
+		set["status"] = EventStatusFailed
+		set["last_error"] = procErr.Error()
+	}
+
+	_, err := s.events.UpdateOne(ctx, bson.M{"_id": eventID}, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("falha ao atualizar o evento %s da Stripe no MongoDB: %w", eventID, err)
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProvisioningJob é o provisionamento de uma conta após o pagamento confirmado,
+// executado em etapas que são refeitas em segundo plano quando falham.
+type ProvisioningJob struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty"`
+	UserID       string             `bson:"user_id"`
+	Domain       string             `bson:"domain"`
+	ContactEmail string             `bson:"contact_email,omitempty"`
+	ProductID    string             `bson:"product_id,omitempty"`
+	// Register indica um domínio disponível, que a equipe registra para o cliente.
+	// Transfer indica um domínio já registrado pelo cliente, cujos DNS precisam
+	// passar a apontar para a conta; SealedAuthCode é o código EPP para transferir o
+	// registro, cifrado pelo serviço de credenciais e apagado após o pedido à equipe.
+	Register       bool   `bson:"register,omitempty"`
+	Transfer       bool   `bson:"transfer,omitempty"`
+	SealedAuthCode []byte `bson:"sealed_auth_code,omitempty"`
+	// Plan e Username são definidos na primeira tentativa de criar a conta e
+	// reaproveitados nas seguintes, para não criar contas duplicadas.
+	Plan      string             `bson:"plan,omitempty"`
+	Username  string             `bson:"username,omitempty"`
+	Steps     []ProvisioningStep `bson:"steps"`
+	Status    string             `bson:"status"`
+	Attempts  int                `bson:"attempts"`
+	LastError string             `bson:"last_error,omitempty"`
+	// NextAttemptAt é o momento a partir do qual o job pode ser executado novamente.
+	NextAttemptAt time.Time `bson:"next_attempt_at"`
+	// LeaseID e LeaseUntil identificam quem executa o job (status running) e até
+	// quando. Um job cuja execução passou do prazo pode ser retomado por outro.
+	LeaseID    primitive.ObjectID `bson:"lease_id,omitempty"`
+	LeaseUntil time.Time          `bson:"lease_until,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at"`
+}
+
+// ProvisioningStep é uma etapa do provisionamento, com situação própria.
+type ProvisioningStep struct {
+	Name        string    `bson:"name"`
+	Status      string    `bson:"status"`
+	Attempts    int       `bson:"attempts"`
+	LastError   string    `bson:"last_error,omitempty"`
+	CompletedAt time.Time `bson:"completed_at,omitempty"`
+}
+
+// Situações possíveis de um job de provisionamento e de suas etapas.
+const (
+	JobStatusPending     = "pending"
+	JobStatusRunning     = "running"
+	JobStatusDone        = "done"
+	JobStatusFailed      = "failed"
+	JobStatusCompensated = "compensated"
+	// StepStatusSkipped marca uma etapa não essencial que esgotou as tentativas e
+	// foi repassada à equipe.
+	StepStatusSkipped = "skipped"
+)
+
+// EnqueueProvisioningJob grava um novo job de provisionamento pendente.
+func (s *MongoStore) EnqueueProvisioningJob(ctx context.Context, job *ProvisioningJob) error {
+	now := time.Now()
+	job.ID = primitive.NewObjectID()
+	job.Status = JobStatusPending
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if job.NextAttemptAt.IsZero() {
+		job.NextAttemptAt = now
+	}
+
+	if _, err := s.jobs.InsertOne(ctx, job); err != nil {
+		return fmt.Errorf("falha ao gravar job de provisionamento no MongoDB: %w", err)
+	}
+	return nil
+}
+
+// ErrLeaseLost indica que outra execução assumiu o job de provisionamento.
+var ErrLeaseLost = errors.New("job de provisionamento assumido por outra execução")
+
+// ClaimDueProvisioningJob assume atomicamente o próximo job pendente cuja tentativa
+// já venceu, ou um job em execução cujo lease expirou. Retorna nil se não houver.
+func (s *MongoStore) ClaimDueProvisioningJob(ctx context.Context, now time.Time, lease time.Duration) (*ProvisioningJob, error) {
+	return s.claimProvisioningJob(ctx, claimableJobs(now), now, lease)
+}
+
+// claimableJobs filtra os jobs que podem ser assumidos por uma nova execução.
+func claimableJobs(now time.Time) bson.M {
+	return bson.M{"$or": bson.A{
+		bson.M{"status": JobStatusPending, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"status": JobStatusRunning, "lease_until": bson.M{"$lte": now}},
+	}}
+}
+
+func (s *MongoStore) claimProvisioningJob(ctx context.Context, filter bson.M, now time.Time, lease time.Duration) (*ProvisioningJob, error) {
+	update := bson.M{"$set": bson.M{
+		"status":      JobStatusRunning,
+		"lease_id":    primitive.NewObjectID(),
+		"lease_until": now.Add(lease),
+		"updated_at":  now,
+	}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"next_attempt_at": 1}).
+		SetReturnDocument(options.After)
+
+	var job ProvisioningJob
+	if err := s.jobs.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("falha ao assumir job de provisionamento no MongoDB: %w", err)
+	}
+	return &job, nil
+}
+
+// SaveProvisioningJob atualiza o estado de um job de provisionamento assumido por
+// ClaimDueProvisioningJob. Retorna ErrLeaseLost se o lease do job passou para outra
+// execução, que passa a ser a única a gravá-lo.
+func (s *MongoStore) SaveProvisioningJob(ctx context.Context, job *ProvisioningJob) error {
+	job.UpdatedAt = time.Now()
+	result, err := s.jobs.ReplaceOne(ctx, bson.M{"_id": job.ID, "lease_id": job.LeaseID}, job)
+	if err != nil {
+		return fmt.Errorf("falha ao salvar job de provisionamento no MongoDB: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrLeaseLost
+	}
+	return nil
+}
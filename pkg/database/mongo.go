@@ -13,8 +13,14 @@ import (
 
 // MongoStore implementa a persistência de sessão com o MongoDB.
 type MongoStore struct {
-	client     *mongo.Client
-	collection *mongo.Collection
+	client        *mongo.Client
+	collection    *mongo.Collection
+	customers     *mongo.Collection
+	events        *mongo.Collection
+	jobs          *mongo.Collection
+	operatorTasks *mongo.Collection
+	credentials   *mongo.Collection
+	transfers     *mongo.Collection
 }
 
 // NewMongoStore cria e retorna uma nova instância de MongoStore.
@@ -35,8 +41,17 @@ func NewMongoStore(ctx context.Context, uri, dbName, collectionName string) (*Mo
 		return nil, fmt.Errorf("falha ao pingar MongoDB (primary): %w", err)
 	}
 
-	collection := client.Database(dbName).Collection(collectionName)
-	return &MongoStore{client: client, collection: collection}, nil
+	db := client.Database(dbName)
+	return &MongoStore{
+		client:        client,
+		collection:    db.Collection(collectionName),
+		customers:     db.Collection("customers"),
+		events:        db.Collection("stripe_events"),
+		jobs:          db.Collection("provisioning_jobs"),
+		operatorTasks: db.Collection("operator_tasks"),
+		credentials:   db.Collection("credential_links"),
+		transfers:     db.Collection("domain_transfers"),
+	}, nil
 }
 
 // Close fecha a conexão com o MongoDB.
@@ -81,3 +96,47 @@ func (s *MongoStore) SaveSession(ctx context.Context, session *Session) error {
 	}
 	return nil
 }
+
+// FindSessionsByState retorna as sessões que estão em um dos estados informados.
+func (s *MongoStore) FindSessionsByState(ctx context.Context, states ...string) ([]Session, error) {
+	filter := bson.M{"state": bson.M{"$in": states}}
+
+	cursor, err := s.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar sessões no MongoDB: %w", err)
+	}
+	var sessions []Session
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return nil, fmt.Errorf("falha ao decodificar sessões do MongoDB: %w", err)
+	}
+	return sessions, nil
+}
+
+// UpdateSession grava apenas os campos informados em set (ex.: "payment.reminders"),
+// desde que a sessão ainda corresponda a match (ex.: o mesmo estado). Evita que as
+// rotinas em segundo plano sobrescrevam uma mensagem processada nesse meio tempo.
+// Retorna false se a sessão mudou e nada foi gravado.
+func (s *MongoStore) UpdateSession(ctx context.Context, userID string, match, set map[string]interface{}) (bool, error) {
+	filter := bson.M{"user_id": userID}
+	for key, value := range match {
+		filter[key] = value
+	}
+
+	result, err := s.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+	if err != nil {
+		return false, fmt.Errorf("falha ao atualizar sessão no MongoDB: %w", err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+// IncrementPaymentReminders registra o envio de um lembrete de pagamento, desde que a
+// sessão ainda esteja no estado informado e com a mesma cobrança (requestedAt).
+// Retorna false se a cobrança mudou e o lembrete não deve ser enviado.
+func (s *MongoStore) IncrementPaymentReminders(ctx context.Context, userID, state string, requestedAt time.Time) (bool, error) {
+	filter := bson.M{"user_id": userID, "state": state, "payment.requested_at": requestedAt}
+	result, err := s.collection.UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"payment.reminders": 1}})
+	if err != nil {
+		return false, fmt.Errorf("falha ao registrar lembrete de pagamento no MongoDB: %w", err)
+	}
+	return result.MatchedCount > 0, nil
+}
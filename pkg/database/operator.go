@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OperatorTask é um item da fila de atendimento da equipe, para situações que
+// exigem acompanhamento humano.
+type OperatorTask struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty"`
+	Kind      string             `bson:"kind"`
+	UserID    string             `bson:"user_id,omitempty"`
+	Summary   string             `bson:"summary"`
+	Link      string             `bson:"link,omitempty"` // Link de uso único de um dado sigiloso (ex.: código EPP)
+	Status    string             `bson:"status"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// Situações possíveis de um item da fila de atendimento.
+const (
+	OperatorTaskOpen   = "open"
+	OperatorTaskClosed = "closed"
+)
+
+// EnqueueOperatorTask adiciona um item aberto à fila de atendimento da equipe.
+func (s *MongoStore) EnqueueOperatorTask(ctx context.Context, task *OperatorTask) error {
+	task.ID = primitive.NewObjectID()
+	task.Status = OperatorTaskOpen
+	task.CreatedAt = time.Now()
+
+	if _, err := s.operatorTasks.InsertOne(ctx, task); err != nil {
+		return fmt.Errorf("falha ao gravar item na fila de atendimento do MongoDB: %w", err)
+	}
+	return nil
+}
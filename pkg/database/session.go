@@ -1,5 +1,7 @@
 package database
 
+import "time"
+
 // PreAnalysisData armazena os dados coletados na fase de pré-análise.
 type PreAnalysisData struct {
 	RepoURL            string `bson:"repo_url,omitempty"`
@@ -7,10 +9,73 @@ type PreAnalysisData struct {
 	ProblemDescription string `bson:"problem_description,omitempty"`
 }
 
+// TechOpsData armazena a classificação do lead Tech Ops feita após a pré-análise.
+type TechOpsData struct {
+	Category     string    `bson:"category,omitempty"`
+	Urgency      string    `bson:"urgency,omitempty"`
+	Complexity   string    `bson:"complexity,omitempty"`
+	Summary      string    `bson:"summary,omitempty"`
+	Source       string    `bson:"source,omitempty"` // "ai" ou "keywords"
+	ClassifiedAt time.Time `bson:"classified_at,omitempty"`
+}
+
+// DNSChangeData armazena a alteração de DNS aguardando confirmação do cliente.
+type DNSChangeData struct {
+	Action   string `bson:"action,omitempty"`
+	Line     int    `bson:"line,omitempty"`
+	Type     string `bson:"type,omitempty"`
+	Name     string `bson:"name,omitempty"`
+	Value    string `bson:"value,omitempty"`
+	Priority int    `bson:"priority,omitempty"`
+}
+
+// PaymentData armazena a cobrança que o cliente ainda precisa pagar.
+type PaymentData struct {
+	Reference   string    `bson:"reference,omitempty"` // Sessão de checkout ou PaymentIntent do Pix
+	Method      string    `bson:"method,omitempty"`
+	URL         string    `bson:"url,omitempty"`
+	RequestedAt time.Time `bson:"requested_at,omitempty"`
+	ExpiresAt   time.Time `bson:"expires_at,omitempty"`
+	Reminders   int       `bson:"reminders,omitempty"`
+}
+
+// OwnershipData armazena o desafio DNS que comprova a titularidade do domínio.
+type OwnershipData struct {
+	Token       string    `bson:"token,omitempty"`
+	RequestedAt time.Time `bson:"requested_at,omitempty"`
+	TimedOut    bool      `bson:"timed_out,omitempty"` // A verificação automática terminou sem encontrar o registro
+}
+
+// TransferData armazena como o domínio escolhido chega à hospedagem: registrado pela
+// equipe, se estava disponível, ou já registrado pelo cliente e apontado para nós.
+type TransferData struct {
+	Register  bool `bson:"register,omitempty"`
+	Requested bool `bson:"requested,omitempty"`
+	// SealedAuthCode é o código EPP para transferir também o registro, cifrado pelo
+	// serviço de credenciais. Sai da sessão quando é copiado para o job de provisionamento.
+	SealedAuthCode []byte `bson:"sealed_auth_code,omitempty"`
+}
+
+// MailboxChangeData armazena a alteração de conta de e-mail em andamento na Área do Cliente.
+type MailboxChangeData struct {
+	Action  string `bson:"action,omitempty"`
+	Address string `bson:"address,omitempty"`
+}
+
 // Session armazena o estado da conversa e outros dados do usuário.
+// SaveSession grava com $set, então os campos que a conversa limpa não usam
+// omitempty: um valor vazio omitido manteria o valor antigo no MongoDB.
 type Session struct {
-	UserID      string          `bson:"user_id"`
-	State       string          `bson:"state"`
-	Domain      string          `bson:"domain,omitempty"`
-	PreAnalysis PreAnalysisData `bson:"pre_analysis,omitempty"`
+	UserID        string            `bson:"user_id"`
+	State         string            `bson:"state"`
+	Domain        string            `bson:"domain"`
+	ProductID     string            `bson:"product_id"`
+	PreAnalysis   PreAnalysisData   `bson:"pre_analysis,omitempty"`
+	DNSChange     DNSChangeData     `bson:"dns_change"`
+	Payment       PaymentData       `bson:"payment"`
+	Ownership     OwnershipData     `bson:"ownership"`
+	Transfer      TransferData      `bson:"transfer"`
+	MailboxChange MailboxChangeData `bson:"mailbox_change"`
+	PendingIntent string            `bson:"pending_intent"`
+	TechOps       TechOpsData       `bson:"tech_ops"`
 }
@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DomainTransfer acompanha um domínio já registrado que passa a ser hospedado
+// conosco, até que seus servidores DNS apontem para a nova conta.
+type DomainTransfer struct {
+	Domain      string   `bson:"_id"`
+	UserID      string   `bson:"user_id"`
+	Username    string   `bson:"username,omitempty"`
+	Nameservers []string `bson:"nameservers"` // Servidores DNS que o domínio deve usar
+	Status      string   `bson:"status"`
+	// CurrentNameservers são os servidores DNS encontrados na última consulta.
+	CurrentNameservers []string  `bson:"current_nameservers,omitempty"`
+	RequestedAt        time.Time `bson:"requested_at"`
+	CheckedAt          time.Time `bson:"checked_at,omitempty"`
+	ReminderSentAt     time.Time `bson:"reminder_sent_at,omitempty"`
+	LiveAt             time.Time `bson:"live_at,omitempty"`
+}
+
+// Situações possíveis do acompanhamento de um domínio transferido.
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusLive      = "live"
+	TransferStatusAbandoned = "abandoned"
+)
+
+// SaveDomainTransfer grava o acompanhamento do domínio, substituindo o anterior.
+func (s *MongoStore) SaveDomainTransfer(ctx context.Context, transfer *DomainTransfer) error {
+	opts := options.Replace().SetUpsert(true)
+	if _, err := s.transfers.ReplaceOne(ctx, bson.M{"_id": transfer.Domain}, transfer, opts); err != nil {
+		return fmt.Errorf("falha ao salvar a transferência do domínio %s no MongoDB: %w", transfer.Domain, err)
+	}
+	return nil
+}
+
+// PendingDomainTransfers retorna os domínios que ainda não apontam para a nova conta.
+func (s *MongoStore) PendingDomainTransfers(ctx context.Context) ([]DomainTransfer, error) {
+	cursor, err := s.transfers.Find(ctx, bson.M{"status": TransferStatusPending})
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar transferências de domínio no MongoDB: %w", err)
+	}
+	var transfers []DomainTransfer
+	if err := cursor.All(ctx, &transfers); err != nil {
+		return nil, fmt.Errorf("falha ao decodificar transferências de domínio do MongoDB: %w", err)
+	}
+	return transfers, nil
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+// Nameservers retorna os servidores DNS publicados para o domínio, em minúsculas e
+// sem o ponto final. Um domínio sem registros NS retorna uma lista vazia.
+func (v *Verifier) Nameservers(ctx context.Context, name string) ([]string, error) {
+	records, err := v.Resolver.LookupNS(ctx, name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("falha ao consultar os servidores DNS de %s: %w", name, err)
+	}
+
+	nameservers := make([]string, 0, len(records))
+	for _, ns := range records {
+		nameservers = append(nameservers, normalizeHost(ns.Host))
+	}
+	sort.Strings(nameservers)
+	return nameservers, nil
+}
+
+// DelegatedTo indica se o domínio usa apenas os servidores DNS esperados e retorna
+// os servidores encontrados.
+func (v *Verifier) DelegatedTo(ctx context.Context, name string, expected []string) (bool, []string, error) {
+	current, err := v.Nameservers(ctx, name)
+	if err != nil || len(current) == 0 {
+		return false, current, err
+	}
+
+	allowed := make(map[string]bool, len(expected))
+	for _, ns := range expected {
+		allowed[normalizeHost(ns)] = true
+	}
+	for _, ns := range current {
+		if !allowed[ns] {
+			return false, current, nil
+		}
+	}
+	return true, current, nil
+}
+
+func normalizeHost(host string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+}
@@ -0,0 +1,106 @@
+// Package domain valida nomes de domínio e consulta sua situação de registro via RDAP.
+package domain
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// brCategories são as categorias de segundo nível do .br em que os clientes
+// registram domínios (ex.: meusite.com.br). A lista completa é mantida pelo Registro.br.
+var brCategories = map[string]bool{
+	"com": true, "net": true, "org": true, "art": true, "blog": true, "eco": true,
+	"edu": true, "emp": true, "gov": true, "ind": true, "inf": true, "info": true,
+	"adv": true, "app": true, "arq": true, "eng": true, "med": true, "mus": true,
+	"nom": true, "odo": true, "psi": true, "rec": true, "seg": true, "srv": true,
+	"tec": true, "tur": true, "tv": true, "dev": true, "log": true, "ong": true,
+}
+
+// idnaProfile converte domínios internacionalizados (ex.: coração.com.br) para a
+// forma ASCII (xn--) usada no DNS e no RDAP.
+var idnaProfile = idna.New(idna.MapForLookup(), idna.BidiRule(), idna.Transitional(false))
+
+// Normalize limpa o texto digitado pelo cliente e retorna o domínio em minúsculas
+// e na forma ASCII. Aceita entradas como "https://www.MeuSite.com.br/contato".
+func Normalize(input string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(input))
+	if i := strings.Index(name, "://"); i >= 0 {
+		name = name[i+3:]
+	}
+	if i := strings.IndexAny(name, "/?#"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "www."), ".")
+	if name == "" {
+		return "", fmt.Errorf("domínio vazio")
+	}
+
+	ascii, err := idnaProfile.ToASCII(name)
+	if err != nil {
+		return "", fmt.Errorf("domínio inválido %q: %w", input, err)
+	}
+	if err := Validate(ascii); err != nil {
+		return "", err
+	}
+	return ascii, nil
+}
+
+// Validate verifica a sintaxe de um domínio na forma ASCII e se ele pode ser
+// registrado (ex.: "meusite.com.br", não "com.br").
+func Validate(name string) error {
+	if len(name) > 253 {
+		return fmt.Errorf("domínio muito longo: %q", name)
+	}
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return fmt.Errorf("informe o domínio completo, com a extensão (ex.: %s.com.br)", name)
+	}
+	for _, label := range labels {
+		if !validLabel(label) {
+			return fmt.Errorf("domínio inválido: %q", name)
+		}
+	}
+
+	tld := labels[len(labels)-1]
+	if !strings.HasPrefix(tld, "xn--") && strings.IndexFunc(tld, func(r rune) bool { return r < 'a' || r > 'z' }) >= 0 {
+		return fmt.Errorf("extensão inválida: %q", tld)
+	}
+	if tld == "br" && len(labels) == 2 && brCategories[labels[0]] {
+		return fmt.Errorf("informe o nome do domínio antes de .%s.br", labels[0])
+	}
+	return nil
+}
+
+// IsBR indica se o domínio pertence ao .br, administrado pelo Registro.br.
+func IsBR(name string) bool {
+	return TLD(name) == "br"
+}
+
+// TLD retorna a extensão de primeiro nível do domínio.
+func TLD(name string) string {
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// Display retorna o domínio na forma Unicode, para exibir ao cliente.
+func Display(name string) string {
+	if unicode, err := idna.ToUnicode(name); err == nil {
+		return unicode
+	}
+	return name
+}
+
+// validLabel verifica um rótulo do domínio: letras, dígitos e hífens, com até 63
+// caracteres, sem hífen no início ou no fim.
+func validLabel(label string) bool {
+	if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{input: "meusite.com.br", want: "meusite.com.br"},
+		{input: "  https://www.MeuSite.com.br/contato?x=1 ", want: "meusite.com.br"},
+		{input: "loja.net.", want: "loja.net"},
+		{input: "coração.com.br", want: "xn--corao-dra1a.com.br"},
+		{input: "CAFÉ.com", want: "xn--caf-dma.com"},
+		{input: "com.br", wantErr: true},
+		{input: "meusite", wantErr: true},
+		{input: "-meusite.com", wantErr: true},
+		{input: "meu site.com", wantErr: true},
+		{input: "meusite.c0m", wantErr: true},
+		{input: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := Normalize(tt.input)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Normalize(%q) = %q, esperava erro", tt.input, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Normalize(%q) = %q, %v; esperava %q", tt.input, got, err, tt.want)
+		}
+	}
+}
+
+func TestDisplay(t *testing.T) {
+	if got := Display("xn--corao-dra1a.com.br"); got != "coração.com.br" {
+		t.Errorf("Display = %q, esperava coração.com.br", got)
+	}
+}
@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Registro TXT que o cliente publica para provar que controla o domínio.
+const (
+	ChallengeLabel  = "_dresbach-verificacao"
+	challengePrefix = "dresbach-verificacao="
+)
+
+// NewChallengeToken gera um token aleatório para o desafio de titularidade.
+func NewChallengeToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("falha ao gerar o token de verificação: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ChallengeName retorna o nome do registro TXT do desafio (ex.: _dresbach-verificacao.meusite.com.br).
+func ChallengeName(name string) string {
+	return ChallengeLabel + "." + name
+}
+
+// ChallengeValue retorna o conteúdo que o registro TXT deve ter.
+func ChallengeValue(token string) string {
+	return challengePrefix + token
+}
+
+// Verifier consulta o DNS para confirmar o desafio de titularidade.
+type Verifier struct {
+	Resolver *net.Resolver
+}
+
+// NewVerifier cria um Verifier. Com server vazio usa o resolvedor do sistema; caso
+// contrário consulta diretamente o servidor informado ("host:porta"), o que evita
+// o cache negativo de resolvedores locais e permite usar um servidor DNS de teste.
+func NewVerifier(server string, timeout time.Duration) *Verifier {
+	if server == "" {
+		return &Verifier{Resolver: net.DefaultResolver}
+	}
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		server = net.JoinHostPort(server, "53")
+	}
+	return &Verifier{Resolver: &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			d := net.Dialer{Timeout: timeout}
+			return d.DialContext(ctx, network, server)
+		},
+	}}
+}
+
+// Verify indica se o registro TXT do desafio já está publicado com o token esperado.
+// A ausência do registro não é um erro: o cliente pode ainda não tê-lo criado.
+func (v *Verifier) Verify(ctx context.Context, name, token string) (bool, error) {
+	records, err := v.Resolver.LookupTXT(ctx, ChallengeName(name))
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("falha ao consultar o registro TXT de %s: %w", name, err)
+	}
+
+	expected := ChallengeValue(token)
+	for _, record := range records {
+		// Alguns painéis gravam o valor entre aspas.
+		if strings.Trim(strings.TrimSpace(record), `"`) == expected {
+			return true, nil
+		}
+	}
+	return false, nil
+}
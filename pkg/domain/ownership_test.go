@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startDNSServer sobe um servidor DNS UDP local que responde às consultas TXT com
+// os registros de records. Nomes ausentes recebem NXDOMAIN e nomes em failing, SERVFAIL.
+func startDNSServer(t *testing.T, records map[string][]string, failing map[string]bool) string {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("falha ao iniciar o servidor DNS de teste: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var query dnsmessage.Message
+			if err := query.Unpack(buf[:n]); err != nil || len(query.Questions) == 0 {
+				continue
+			}
+			reply, err := dnsReply(query, records, failing)
+			if err != nil {
+				t.Errorf("falha ao montar a resposta DNS: %v", err)
+				return
+			}
+			conn.WriteTo(reply, addr)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func dnsReply(query dnsmessage.Message, records map[string][]string, failing map[string]bool) ([]byte, error) {
+	question := query.Questions[0]
+	name := strings.TrimSuffix(strings.ToLower(question.Name.String()), ".")
+
+	reply := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: query.ID, Response: true, Authoritative: true, RecursionAvailable: true},
+		Questions: query.Questions,
+	}
+	values, ok := records[name]
+	switch {
+	case failing[name]:
+		reply.RCode = dnsmessage.RCodeServerFailure
+	case !ok:
+		reply.RCode = dnsmessage.RCodeNameError
+	case question.Type == dnsmessage.TypeTXT:
+		for _, value := range values {
+			reply.Answers = append(reply.Answers, dnsmessage.Resource{
+				Header: dnsmessage.ResourceHeader{Name: question.Name, Type: dnsmessage.TypeTXT, Class: dnsmessage.ClassINET, TTL: 60},
+				Body:   &dnsmessage.TXTResource{TXT: []string{value}},
+			})
+		}
+	}
+	return reply.Pack()
+}
+
+func TestVerify(t *testing.T) {
+	const token = "0123456789abcdef"
+	server := startDNSServer(t, map[string][]string{
+		ChallengeName("meusite.com.br"): {"v=spf1 -all", ChallengeValue(token)},
+		ChallengeName("aspas.com.br"):   {`"` + ChallengeValue(token) + `"`},
+		ChallengeName("outro.com.br"):   {ChallengeValue("outro-token")},
+	}, map[string]bool{
+		ChallengeName("quebrado.com.br"): true,
+	})
+	verifier := NewVerifier(server, 2*time.Second)
+
+	tests := []struct {
+		name    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "meusite.com.br", want: true},
+		{name: "aspas.com.br", want: true},    // Valor gravado entre aspas pelo painel
+		{name: "outro.com.br", want: false},   // Token de outra sessão
+		{name: "ausente.com.br", want: false}, // NXDOMAIN: o cliente ainda não criou o registro
+		{name: "quebrado.com.br", wantErr: true},
+	}
+	for _, tt := range tests {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		got, err := verifier.Verify(ctx, tt.name, token)
+		cancel()
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Verify(%q) = %v, esperava erro", tt.name, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("Verify(%q) = %v, %v; esperava %v", tt.name, got, err, tt.want)
+		}
+	}
+}
+
+func TestNewVerifierSystemResolver(t *testing.T) {
+	if v := NewVerifier("", time.Second); v.Resolver != net.DefaultResolver {
+		t.Error("sem servidor, o Verifier deveria usar o resolvedor do sistema")
+	}
+}
@@ -0,0 +1,213 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Endereços públicos do RDAP usados por padrão.
+const (
+	DefaultRegistroBRURL = "https://rdap.registro.br"
+	DefaultBootstrapURL  = "https://data.iana.org/rdap/dns.json"
+)
+
+// bootstrapTTL é por quanto tempo a tabela de servidores RDAP da IANA é reaproveitada.
+const bootstrapTTL = 24 * time.Hour
+
+// Status é a situação de registro de um domínio.
+type Status struct {
+	Domain      string
+	Registered  bool
+	Holder      string // Titular, quando o RDAP o informa
+	Nameservers []string
+	ExpiresAt   time.Time
+}
+
+// Checker consulta a situação de domínios via RDAP: o Registro.br para o .br e o
+// servidor indicado pelo bootstrap da IANA para as demais extensões. Os endereços
+// podem apontar para um servidor local nos testes.
+type Checker struct {
+	RegistroBRURL string
+	BootstrapURL  string
+	HTTPClient    *http.Client
+
+	mu        sync.Mutex
+	services  map[string]string // TLD -> URL base do RDAP
+	fetchedAt time.Time
+}
+
+// NewChecker cria um Checker com os endereços públicos e o timeout informado.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{
+		RegistroBRURL: DefaultRegistroBRURL,
+		BootstrapURL:  DefaultBootstrapURL,
+		HTTPClient:    &http.Client{Timeout: timeout},
+	}
+}
+
+// rdapDomain reflete os campos usados da resposta RDAP de um domínio (RFC 9083).
+type rdapDomain struct {
+	LDHName  string `json:"ldhName"`
+	Entities []struct {
+		Roles      []string        `json:"roles"`
+		VCardArray json.RawMessage `json:"vcardArray"`
+	} `json:"entities"`
+	Nameservers []struct {
+		LDHName string `json:"ldhName"`
+	} `json:"nameservers"`
+	Events []struct {
+		Action string `json:"eventAction"`
+		Date   string `json:"eventDate"`
+	} `json:"events"`
+}
+
+// Lookup consulta a situação de registro do domínio, já normalizado.
+func (c *Checker) Lookup(ctx context.Context, name string) (*Status, error) {
+	base, err := c.serverFor(ctx, TLD(name))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", strings.TrimRight(base, "/")+"/domain/"+name, nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar a consulta RDAP: %w", err)
+	}
+	req.Header.Set("Accept", "application/rdap+json")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("falha na consulta RDAP do domínio %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return &Status{Domain: name}, nil
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("consulta RDAP do domínio %s retornou %s", name, resp.Status)
+	}
+
+	var data rdapDomain
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("falha ao decodificar a resposta RDAP do domínio %s: %w", name, err)
+	}
+
+	status := &Status{Domain: name, Registered: true}
+	for _, ns := range data.Nameservers {
+		status.Nameservers = append(status.Nameservers, strings.ToLower(strings.TrimSuffix(ns.LDHName, ".")))
+	}
+	for _, event := range data.Events {
+		if event.Action == "expiration" {
+			status.ExpiresAt, _ = time.Parse(time.RFC3339, event.Date)
+		}
+	}
+	for _, entity := range data.Entities {
+		if hasRole(entity.Roles, "registrant") {
+			status.Holder = vcardName(entity.VCardArray)
+			break
+		}
+	}
+	return status, nil
+}
+
+// serverFor retorna o servidor RDAP responsável pela extensão.
+func (c *Checker) serverFor(ctx context.Context, tld string) (string, error) {
+	if tld == "br" {
+		return c.RegistroBRURL, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.services == nil || time.Since(c.fetchedAt) > bootstrapTTL {
+		services, err := c.fetchBootstrap(ctx)
+		if err != nil {
+			return "", err
+		}
+		c.services = services
+		c.fetchedAt = time.Now()
+	}
+
+	base, ok := c.services[tld]
+	if !ok {
+		return "", fmt.Errorf("nenhum servidor RDAP conhecido para a extensão .%s", tld)
+	}
+	return base, nil
+}
+
+// fetchBootstrap carrega a tabela de servidores RDAP por extensão (RFC 9224).
+func (c *Checker) fetchBootstrap(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.BootstrapURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar a consulta ao bootstrap RDAP: %w", err)
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao consultar o bootstrap RDAP: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("bootstrap RDAP retornou %s", resp.Status)
+	}
+
+	var bootstrap struct {
+		Services [][][]string `json:"services"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&bootstrap); err != nil {
+		return nil, fmt.Errorf("falha ao decodificar o bootstrap RDAP: %w", err)
+	}
+
+	services := make(map[string]string)
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+		// Prefere o endereço HTTPS quando o serviço lista mais de um.
+		base := service[1][0]
+		for _, u := range service[1] {
+			if strings.HasPrefix(u, "https://") {
+				base = u
+				break
+			}
+		}
+		for _, tld := range service[0] {
+			services[strings.ToLower(tld)] = base
+		}
+	}
+	return services, nil
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// vcardName extrai o nome (fn) de um jCard (RFC 7095): ["vcard", [["fn", {}, "text", "Nome"], ...]].
+func vcardName(raw json.RawMessage) string {
+	var card []json.RawMessage
+	if err := json.Unmarshal(raw, &card); err != nil || len(card) < 2 {
+		return ""
+	}
+	var props [][]json.RawMessage
+	if err := json.Unmarshal(card[1], &props); err != nil {
+		return ""
+	}
+	for _, prop := range props {
+		if len(prop) < 4 {
+			continue
+		}
+		var key, value string
+		if json.Unmarshal(prop[0], &key) == nil && key == "fn" && json.Unmarshal(prop[3], &value) == nil {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,132 @@
+package domain
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const registeredDomain = `{
+	"ldhName": "meusite.com.br",
+	"entities": [
+		{"roles": ["technical"], "vcardArray": ["vcard", [["fn", {}, "text", "Contato Técnico"]]]},
+		{"roles": ["registrant"], "vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Empresa Exemplo LTDA"]]]}
+	],
+	"nameservers": [{"ldhName": "NS1.EXEMPLO.COM.BR."}, {"ldhName": "ns2.exemplo.com.br"}],
+	"events": [
+		{"eventAction": "registration", "eventDate": "2020-01-10T12:00:00Z"},
+		{"eventAction": "expiration", "eventDate": "2030-01-10T12:00:00Z"}
+	]
+}`
+
+// newRDAPServer simula o Registro.br, o bootstrap da IANA e o RDAP do .com. Domínios
+// fora de registered retornam 404, como um domínio disponível.
+func newRDAPServer(t *testing.T, registered map[string]string, bootstrapHits *int) *httptest.Server {
+	t.Helper()
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/dns.json":
+			*bootstrapHits++
+			fmt.Fprintf(w, `{"services": [[["COM"], ["%s/com/"]], [["net"], ["http://rdap.exemplo.invalid/", "https://rdap.exemplo.invalid/"]]]}`, srv.URL)
+		case strings.HasPrefix(r.URL.Path, "/br/domain/"), strings.HasPrefix(r.URL.Path, "/com/domain/"):
+			name := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+			body, ok := registered[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", "application/rdap+json")
+			fmt.Fprint(w, body)
+		default:
+			http.Error(w, "erro", http.StatusInternalServerError)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestChecker(srv *httptest.Server) *Checker {
+	return &Checker{
+		RegistroBRURL: srv.URL + "/br",
+		BootstrapURL:  srv.URL + "/dns.json",
+		HTTPClient:    srv.Client(),
+	}
+}
+
+func TestLookupRegisteredBR(t *testing.T) {
+	var hits int
+	srv := newRDAPServer(t, map[string]string{"meusite.com.br": registeredDomain}, &hits)
+	checker := newTestChecker(srv)
+
+	status, err := checker.Lookup(context.Background(), "meusite.com.br")
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if !status.Registered || status.Holder != "Empresa Exemplo LTDA" {
+		t.Errorf("status = %+v, esperava registrado por Empresa Exemplo LTDA", status)
+	}
+	if got := strings.Join(status.Nameservers, ","); got != "ns1.exemplo.com.br,ns2.exemplo.com.br" {
+		t.Errorf("Nameservers = %q", got)
+	}
+	if want := time.Date(2030, 1, 10, 12, 0, 0, 0, time.UTC); !status.ExpiresAt.Equal(want) {
+		t.Errorf("ExpiresAt = %v, esperava %v", status.ExpiresAt, want)
+	}
+	if hits != 0 {
+		t.Errorf("o .br não deveria consultar o bootstrap da IANA (%d consultas)", hits)
+	}
+}
+
+func TestLookupAvailable(t *testing.T) {
+	var hits int
+	srv := newRDAPServer(t, nil, &hits)
+	checker := newTestChecker(srv)
+
+	for _, name := range []string{"disponivel.com.br", "disponivel.com", "xn--caf-dma.com"} {
+		status, err := checker.Lookup(context.Background(), name)
+		if err != nil {
+			t.Fatalf("Lookup(%q): %v", name, err)
+		}
+		if status.Registered {
+			t.Errorf("Lookup(%q) = registrado, esperava disponível", name)
+		}
+	}
+	if hits != 1 {
+		t.Errorf("bootstrap consultado %d vezes, esperava 1 (reaproveitado em cache)", hits)
+	}
+}
+
+func TestLookupErrors(t *testing.T) {
+	var hits int
+	srv := newRDAPServer(t, nil, &hits)
+	checker := newTestChecker(srv)
+
+	// Extensão ausente do bootstrap.
+	if _, err := checker.Lookup(context.Background(), "meusite.org"); err == nil {
+		t.Error("Lookup(meusite.org) deveria falhar sem servidor RDAP para .org")
+	}
+
+	// Servidor RDAP com erro: o domínio não pode ser dado como disponível.
+	checker.RegistroBRURL = srv.URL + "/quebrado"
+	if status, err := checker.Lookup(context.Background(), "meusite.com.br"); err == nil {
+		t.Errorf("Lookup com o servidor em erro = %+v, esperava erro", status)
+	}
+}
+
+func TestServerForPrefersHTTPS(t *testing.T) {
+	var hits int
+	srv := newRDAPServer(t, nil, &hits)
+	checker := newTestChecker(srv)
+
+	base, err := checker.serverFor(context.Background(), "net")
+	if err != nil {
+		t.Fatalf("serverFor: %v", err)
+	}
+	if base != "https://rdap.exemplo.invalid/" {
+		t.Errorf("serverFor(net) = %q", base)
+	}
+}
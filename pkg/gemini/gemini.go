@@ -4,17 +4,23 @@ import (
 	"context"
 	"log"
 
+	"github.com/dresbach/dresbach-assistente/pkg/guardrails"
 	"github.com/google/generative-ai-go/genai"
 	"google.golang.org/api/option"
 )
 
+// DefaultModel é o modelo usado quando nenhum é configurado.
+const DefaultModel = "gemini-1.5-flash"
+
 // Client é um wrapper para o modelo generativo do Gemini.
 type Client struct {
-	model *genai.GenerativeModel
+	client    *genai.Client
+	model     *genai.GenerativeModel
+	modelName string
 }
 
-// NewClient cria um novo cliente Gemini.
-func NewClient(apiKey string) (*Client, error) {
+// NewClient cria um novo cliente Gemini para o modelo informado.
+func NewClient(apiKey, modelName string) (*Client, error) {
 	ctx := context.Background()
 
 	// Primeiro, crie o cliente com a chave da API.
@@ -23,13 +29,27 @@ func NewClient(apiKey string) (*Client, error) {
 		return nil, err
 	}
 
+	if modelName == "" {
+		modelName = DefaultModel
+	}
 	// Em seguida, obtenha o modelo generativo do cliente.
-	model := client.GenerativeModel("gemini-pro")
+	model := client.GenerativeModel(modelName)
 
-	return &Client{model: model}, nil
+	return &Client{client: client, model: model, modelName: modelName}, nil
 }
 
-// GenerateText gera uma resposta de texto a partir de um prompt.
+// Close encerra a conexão com a API do Gemini.
+func (c *Client) Close() error {
+	return c.client.Close()
+}
+
+// GenerateText gera uma resposta de texto a partir de um prompt. A resposta passa
+// pelas regras de negócio de guardrails antes de ser retornada.
+//
+// Nenhum fluxo da conversa chama GenerateText ainda: Classify e ClassifyLead
+// retornam apenas dados estruturados, e o resumo do lead vai só para a equipe.
+// O fluxo que passar a enviar texto gerado ao cliente deve usar GenerateText, e
+// não GenerateContent diretamente.
 func (c *Client) GenerateText(prompt string) (string, error) {
 	ctx := context.Background()
 	resp, err := c.model.GenerateContent(ctx, genai.Text(prompt))
@@ -37,21 +57,30 @@ func (c *Client) GenerateText(prompt string) (string, error) {
 		return "", err
 	}
 
-	var responseText string
+	responseText := responseText(resp)
+	if responseText == "" {
+		log.Println("Resposta do Gemini vazia ou em formato inesperado.")
+		return "Desculpe, não consegui processar sua solicitação no momento.", nil
+	}
+
+	reply, violations := guardrails.Apply(responseText, guardrails.FallbackReply)
+	for _, v := range violations {
+		log.Printf("AVISO: Resposta do Gemini violou a regra %s: %q", v.Rule, v.Detail)
+	}
+	return reply, nil
+}
+
+// responseText concatena as partes de texto de todos os candidatos da resposta.
+func responseText(resp *genai.GenerateContentResponse) string {
+	var text string
 	for _, cand := range resp.Candidates {
 		if cand.Content != nil {
 			for _, part := range cand.Content.Parts {
 				if txt, ok := part.(genai.Text); ok {
-					responseText += string(txt)
+					text += string(txt)
 				}
 			}
 		}
 	}
-
-	if responseText == "" {
-		log.Println("Resposta do Gemini vazia ou em formato inesperado.")
-		return "Desculpe, não consegui processar sua solicitação no momento.", nil
-	}
-
-	return responseText, nil
+	return text
 }
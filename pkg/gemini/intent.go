@@ -0,0 +1,68 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dresbach/dresbach-assistente/pkg/intent"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// intentInstruction descreve as intenções ao modelo. O texto do cliente vai
+// separado, como conteúdo do usuário, e nunca como instrução.
+const intentInstruction = `Você classifica mensagens de clientes da Dresbach Hosting recebidas pelo WhatsApp.
+Escolha a intenção principal da mensagem:
+- tech_ops: consultoria técnica, diagnóstico, segurança, arquitetura de sistemas, LGPD, performance de sistemas.
+- client_area: cliente que já tem hospedagem e quer faturas, DNS, e-mails, certificado SSL ou dados da conta.
+- support: problema técnico em site ou serviço já contratado (fora do ar, erro, lentidão).
+- sales: contratar hospedagem, conhecer planos e preços de hospedagem, registrar domínio.
+- human: pede explicitamente para falar com uma pessoa.
+- unknown: saudação, mensagem vaga ou assunto não relacionado.
+Informe em confidence, de 0 a 1, o quanto você tem certeza.`
+
+// intentSchema restringe a resposta do modelo ao JSON esperado.
+var intentSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"intent":     {Type: genai.TypeString, Format: "enum", Enum: intentNames()},
+		"confidence": {Type: genai.TypeNumber},
+	},
+	Required: []string{"intent", "confidence"},
+}
+
+// Classify classifica o texto do cliente em uma das opções do menu principal.
+// Implementa intent.Classifier.
+func (c *Client) Classify(ctx context.Context, text string) (intent.Classification, error) {
+	model := c.client.GenerativeModel(c.modelName)
+	model.SystemInstruction = genai.NewUserContent(genai.Text(intentInstruction))
+	model.SetTemperature(0)
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = intentSchema
+
+	resp, err := model.GenerateContent(ctx, genai.Text(text))
+	if err != nil {
+		return intent.Classification{}, fmt.Errorf("falha ao classificar a mensagem no Gemini: %w", err)
+	}
+
+	var result struct {
+		Intent     string  `json:"intent"`
+		Confidence float64 `json:"confidence"`
+	}
+	if err := json.Unmarshal([]byte(responseText(resp)), &result); err != nil {
+		return intent.Classification{}, fmt.Errorf("resposta de classificação do Gemini inválida: %w", err)
+	}
+	classification := intent.Classification{Intent: intent.Intent(result.Intent), Confidence: result.Confidence}
+	if !classification.Intent.Valid() || classification.Confidence < 0 || classification.Confidence > 1 {
+		return intent.Classification{}, fmt.Errorf("classificação do Gemini fora do esperado: %+v", result)
+	}
+	return classification, nil
+}
+
+func intentNames() []string {
+	names := make([]string, len(intent.All))
+	for i, name := range intent.All {
+		names[i] = string(name)
+	}
+	return names
+}
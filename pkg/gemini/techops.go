@@ -0,0 +1,77 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/dresbach/dresbach-assistente/pkg/techops"
+	"github.com/google/generative-ai-go/genai"
+)
+
+// leadInstruction orienta a classificação dos leads do Tech Ops. Os dados do
+// cliente vão separados, como conteúdo do usuário, e nunca como instrução.
+const leadInstruction = `Você é um consultor sênior da Dresbach Tech Ops e faz a triagem de novos clientes.
+Classifique o caso a partir da descrição do problema e dos links informados:
+- category: security (incidentes, vulnerabilidades), architecture (desenho, escalabilidade, migração),
+  lgpd (adequação à LGPD e governança de dados), performance (lentidão, consumo de recursos),
+  legal (questões contratuais ou jurídicas, notificações de órgãos).
+- urgency: low, medium, high ou critical (incidente em andamento, vazamento ou sistema fora do ar).
+- complexity: low, medium ou high.
+- summary: resumo objetivo em português, com até 3 frases, para a equipe. Não informe preços nem prazos.`
+
+// leadSchema restringe a resposta do modelo ao JSON esperado.
+var leadSchema = &genai.Schema{
+	Type: genai.TypeObject,
+	Properties: map[string]*genai.Schema{
+		"category":   {Type: genai.TypeString, Format: "enum", Enum: techops.CategoryNames()},
+		"urgency":    {Type: genai.TypeString, Format: "enum", Enum: techops.Urgencies},
+		"complexity": {Type: genai.TypeString, Format: "enum", Enum: techops.Complexities},
+		"summary":    {Type: genai.TypeString},
+	},
+	Required: []string{"category", "urgency", "complexity", "summary"},
+}
+
+// ClassifyLead classifica o lead do Tech Ops com saída estruturada e valida o
+// resultado. Implementa techops.Classifier.
+func (c *Client) ClassifyLead(ctx context.Context, lead techops.Lead) (techops.Assessment, error) {
+	model := c.client.GenerativeModel(c.modelName)
+	model.SystemInstruction = genai.NewUserContent(genai.Text(leadInstruction))
+	model.SetTemperature(0)
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = leadSchema
+
+	input, err := json.Marshal(map[string]string{
+		"problem_description": lead.ProblemDescription,
+		"repo_url":            lead.RepoURL,
+		"system_url":          lead.SystemURL,
+	})
+	if err != nil {
+		return techops.Assessment{}, err
+	}
+
+	resp, err := model.GenerateContent(ctx, genai.Text(input))
+	if err != nil {
+		return techops.Assessment{}, fmt.Errorf("falha ao classificar o lead no Gemini: %w", err)
+	}
+
+	var result struct {
+		Category   string `json:"category"`
+		Urgency    string `json:"urgency"`
+		Complexity string `json:"complexity"`
+		Summary    string `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(responseText(resp)), &result); err != nil {
+		return techops.Assessment{}, fmt.Errorf("resposta de classificação do lead do Gemini inválida: %w", err)
+	}
+	assessment := techops.Assessment{
+		Category:   techops.Category(result.Category),
+		Urgency:    result.Urgency,
+		Complexity: result.Complexity,
+		Summary:    result.Summary,
+	}
+	if err := assessment.Validate(); err != nil {
+		return techops.Assessment{}, fmt.Errorf("classificação do lead do Gemini fora do esquema: %w", err)
+	}
+	return assessment, nil
+}
@@ -0,0 +1,135 @@
+// Package guardrails aplica as regras de negócio às respostas geradas por IA antes
+// que cheguem ao cliente: sem preços de projeto, uma pergunta por mensagem, sem
+// promessas de prazo e com a linguagem da marca.
+//
+// Por enquanto o pacote é só a base: as regras rodam em gemini.Client.GenerateText,
+// mas nenhum fluxo da conversa envia ao cliente texto gerado pelo modelo.
+package guardrails
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Regras verificadas em cada resposta.
+const (
+	RulePrice     = "price"
+	RuleQuestions = "multiple_questions"
+	RuleDeadline  = "deadline_promise"
+	RuleOffBrand  = "off_brand"
+)
+
+// FallbackReply é a resposta segura usada quando a resposta gerada não pode ser aproveitada.
+const FallbackReply = "Obrigado pela mensagem! Para te orientar da melhor forma, um especialista da Dresbach vai avaliar o seu caso. " +
+	"Pode me contar um pouco mais sobre o que você precisa?"
+
+// minReplyLength é o tamanho mínimo de uma resposta reescrita para ser enviada.
+const minReplyLength = 20
+
+// Violation é uma regra descumprida pela resposta.
+type Violation struct {
+	Rule   string
+	Detail string // Trecho que descumpriu a regra
+}
+
+// rule verifica uma frase da resposta.
+type rule struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// sentenceRules são verificadas frase a frase; frases que as descumprem são removidas
+// na reescrita. Os padrões consideram o texto em minúsculas.
+var sentenceRules = []rule{
+	{RulePrice, regexp.MustCompile(`r\$\s*\d|\d[\d.]*,\d{2}` + "|" +
+		words(`\d+ (mil )?reais`, `(preço|valor|custo|orçamento) (do|de|desse|deste) (projeto|desenvolvimento|sistema|serviço)`,
+			`custa`, `custaria`, `sai por`, `fica em torno de`))},
+	{RuleDeadline, regexp.MustCompile(words(
+		`(em|até|dentro de) (\d+|um|uma|dois|duas|três|poucos|poucas) (horas?|dias?|semanas?|mês|meses)`,
+		`dias úteis`, `(até|para) (amanhã|hoje|segunda|terça|quarta|quinta|sexta)`,
+		`garantimos`, `garanto`, `prometo`, `prometemos`, `prazo de \d+`))},
+	{RuleOffBrand, regexp.MustCompile(words(
+		`mano`, `véi`, `tipo assim`, `blz`, `vc`, `vcs`, `tá ligado`, `k{3,}`, `porra`, `merda`, `caralho`,
+		`como uma ia`, `modelo de linguagem`, `sou um chatbot`))},
+}
+
+// words monta um padrão que só aceita as alternativas como palavras inteiras. O \b
+// do pacote regexp não reconhece letras acentuadas, por isso as bordas são explícitas.
+func words(alternatives ...string) string {
+	return `(?:^|[^\p{L}\p{N}])(?:` + strings.Join(alternatives, "|") + `)(?:$|[^\p{L}\p{N}])`
+}
+
+// sentencePattern separa a resposta em frases, mantendo a pontuação final. O ponto
+// entre dígitos, como em R$ 1.500,00, não encerra a frase.
+var sentencePattern = regexp.MustCompile(`(?:\d[.,]\d|[^.!?\n])+[.!?]*|\n+`)
+
+// Check retorna as regras descumpridas pela resposta.
+func Check(reply string) []Violation {
+	var violations []Violation
+	questions := 0
+	for _, sentence := range sentences(reply) {
+		if v, ok := checkSentence(sentence); ok {
+			violations = append(violations, v)
+		}
+		if strings.Contains(sentence, "?") {
+			questions++
+			if questions == 2 {
+				violations = append(violations, Violation{Rule: RuleQuestions, Detail: strings.TrimSpace(sentence)})
+			}
+		}
+	}
+	return violations
+}
+
+// Apply verifica a resposta e, se necessário, a reescreve removendo as frases que
+// descumprem as regras e as perguntas além da primeira. Se não sobrar uma resposta
+// aproveitável, retorna fallback. As violações encontradas na resposta original
+// são retornadas para registro.
+func Apply(reply, fallback string) (string, []Violation) {
+	violations := Check(reply)
+	if len(violations) == 0 {
+		return strings.TrimSpace(reply), nil
+	}
+
+	var b strings.Builder
+	asked := false
+	for _, sentence := range sentences(reply) {
+		if _, ok := checkSentence(sentence); ok {
+			continue
+		}
+		if strings.Contains(sentence, "?") {
+			if asked {
+				continue
+			}
+			asked = true
+		}
+		b.WriteString(sentence)
+	}
+
+	rewritten := strings.TrimSpace(collapseBlankLines(b.String()))
+	if len([]rune(rewritten)) < minReplyLength || len(Check(rewritten)) > 0 {
+		return fallback, violations
+	}
+	return rewritten, violations
+}
+
+// checkSentence retorna a primeira regra que a frase descumpre.
+func checkSentence(sentence string) (Violation, bool) {
+	lower := strings.ToLower(sentence)
+	for _, r := range sentenceRules {
+		if r.pattern.MatchString(lower) {
+			return Violation{Rule: r.name, Detail: strings.TrimSpace(sentence)}, true
+		}
+	}
+	return Violation{}, false
+}
+
+func sentences(text string) []string {
+	return sentencePattern.FindAllString(text, -1)
+}
+
+var blankLines = regexp.MustCompile(`\n{3,}`)
+
+func collapseBlankLines(text string) string {
+	return blankLines.ReplaceAllString(text, "\n\n")
+}
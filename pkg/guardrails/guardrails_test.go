@@ -0,0 +1,115 @@
+package guardrails
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name  string
+		reply string
+		want  []string
+	}{
+		{name: "resposta adequada", reply: "Entendi o seu cenário. Qual é o endereço do sistema?"},
+		{name: "preço em reais", reply: "O projeto fica R$ 1.500,00.", want: []string{RulePrice}},
+		{name: "preço sem espaço", reply: "Sai a partir de R$800 por mês.", want: []string{RulePrice}},
+		{name: "valor com centavos", reply: "O total é 2.300,00 no cartão.", want: []string{RulePrice}},
+		{name: "preço por extenso", reply: "Algo em torno de 2 mil reais.", want: []string{RulePrice}},
+		{name: "valor do projeto", reply: "O valor do projeto depende do escopo.", want: []string{RulePrice}},
+		{name: "números sem preço", reply: "Atendemos mais de 2 mil clientes.", want: nil},
+		{name: "segunda pergunta", reply: "Qual é o domínio? Ele já está no ar?", want: []string{RuleQuestions}},
+		{name: "prazo em dias", reply: "Conseguimos entregar em 3 dias.", want: []string{RuleDeadline}},
+		{name: "prazo por extenso", reply: "Resolvemos dentro de duas semanas.", want: []string{RuleDeadline}},
+		{name: "dias úteis", reply: "A migração leva 5 dias úteis.", want: []string{RuleDeadline}},
+		{name: "prazo com acento no fim", reply: "Fica pronto até amanhã.", want: []string{RuleDeadline}},
+		{name: "prazo de um mês", reply: "Entregamos em um mês.", want: []string{RuleDeadline}},
+		{name: "garantia", reply: "Garantimos que o site não cai mais.", want: []string{RuleDeadline}},
+		{name: "sem promessa de prazo", reply: "Depois do diagnóstico, definimos o cronograma com você.", want: nil},
+		{name: "gíria", reply: "Blz, pode mandar o link.", want: []string{RuleOffBrand}},
+		{name: "gíria com acento", reply: "Fala, véi!", want: []string{RuleOffBrand}},
+		{name: "gíria com acento no início", reply: "Tá ligado que isso é grave.", want: []string{RuleOffBrand}},
+		{name: "menção à IA", reply: "Como uma IA, não consigo acessar o servidor.", want: []string{RuleOffBrand}},
+		{name: "termo dentro de outra palavra", reply: "Um atendente humano, o Emanoel, vai falar com você.", want: nil},
+		{name: "termo dentro de palavra acentuada", reply: "O relatório é ávido por dados, não há vcé.", want: nil},
+		{
+			name:  "várias regras",
+			reply: "Custa R$ 900. Entregamos em 2 dias? Qual o domínio?",
+			want:  []string{RulePrice, RuleDeadline, RuleQuestions},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range Check(tt.reply) {
+				got = append(got, v.Rule)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check(%q) = %v, esperava %v", tt.reply, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	const fallback = "resposta segura"
+	tests := []struct {
+		name           string
+		reply          string
+		want           string
+		wantViolations int
+	}{
+		{
+			name:  "resposta adequada",
+			reply: "  Entendi o seu cenário. Qual é o endereço do sistema?\n",
+			want:  "Entendi o seu cenário. Qual é o endereço do sistema?",
+		},
+		{
+			name:           "corta a segunda pergunta",
+			reply:          "Entendi o seu cenário. Qual é o domínio do sistema? Ele já está no ar?",
+			want:           "Entendi o seu cenário. Qual é o domínio do sistema?",
+			wantViolations: 1,
+		},
+		{
+			name:           "remove a frase com preço",
+			reply:          "Esse tipo de projeto custa 2 mil reais. Antes, fazemos um diagnóstico do seu ambiente.",
+			want:           "Antes, fazemos um diagnóstico do seu ambiente.",
+			wantViolations: 1,
+		},
+		{
+			name:           "remove a frase com valor decimal",
+			reply:          "Esse plano fica R$ 1.500,00 por ano. Antes, fazemos um diagnóstico do seu ambiente.",
+			want:           "Antes, fazemos um diagnóstico do seu ambiente.",
+			wantViolations: 1,
+		},
+		{
+			name:           "remove a promessa de prazo",
+			reply:          "Entendi o problema.\n\nResolvemos em 2 dias.\n\nPode me enviar o endereço do site?",
+			want:           "Entendi o problema.\n\nPode me enviar o endereço do site?",
+			wantViolations: 1,
+		},
+		{
+			name:           "reescrita curta demais",
+			reply:          "O projeto sai por R$ 1.500,00. Ok!",
+			want:           fallback,
+			wantViolations: 1,
+		},
+		{
+			name:           "nada aproveitável",
+			reply:          "Blz, vc tá ligado?",
+			want:           fallback,
+			wantViolations: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, violations := Apply(tt.reply, fallback)
+			if got != tt.want {
+				t.Errorf("Apply(%q) = %q, esperava %q", tt.reply, got, tt.want)
+			}
+			if len(violations) != tt.wantViolations {
+				t.Errorf("Apply(%q) retornou %d violações, esperava %d: %v", tt.reply, len(violations), tt.wantViolations, violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package intent
+
+import "context"
+
+// Fake é um Classifier com respostas fixas, para testes e desenvolvimento local.
+// Textos sem resposta cadastrada são classificados como Unknown.
+type Fake struct {
+	Responses map[string]Classification
+	Err       error
+	// Calls registra os textos recebidos, na ordem.
+	Calls []string
+}
+
+// Classify retorna a resposta cadastrada para o texto.
+func (f *Fake) Classify(ctx context.Context, text string) (Classification, error) {
+	f.Calls = append(f.Calls, text)
+	if f.Err != nil {
+		return Classification{}, f.Err
+	}
+	if c, ok := f.Responses[text]; ok {
+		return c, nil
+	}
+	return Classification{Intent: Unknown}, nil
+}
@@ -0,0 +1,41 @@
+// Package intent classifica o texto livre do cliente nas opções do menu principal.
+package intent
+
+import "context"
+
+// Intent é a opção do menu principal que o cliente procura.
+type Intent string
+
+const (
+	TechOps    Intent = "tech_ops"    // Consultoria e diagnóstico Tech Ops
+	ClientArea Intent = "client_area" // Área do Cliente de hospedagem
+	Support    Intent = "support"     // Suporte técnico
+	Sales      Intent = "sales"       // Contratação de planos de hospedagem
+	Human      Intent = "human"       // Atendimento humano
+	Unknown    Intent = "unknown"
+)
+
+// All lista as intenções reconhecidas, incluindo Unknown.
+var All = []Intent{TechOps, ClientArea, Support, Sales, Human, Unknown}
+
+// Valid indica se a intenção é uma das reconhecidas.
+func (i Intent) Valid() bool {
+	for _, known := range All {
+		if i == known {
+			return true
+		}
+	}
+	return false
+}
+
+// Classification é o resultado da classificação de uma mensagem.
+type Classification struct {
+	Intent Intent
+	// Confidence vai de 0 a 1.
+	Confidence float64
+}
+
+// Classifier classifica o texto livre do cliente em uma intenção.
+type Classifier interface {
+	Classify(ctx context.Context, text string) (Classification, error)
+}
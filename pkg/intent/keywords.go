@@ -0,0 +1,51 @@
+package intent
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// keywords relaciona termos frequentes a cada intenção, sem acentos e em minúsculas.
+// A ordem importa: pedidos de atendimento humano têm prioridade sobre os demais.
+var keywords = []struct {
+	intent Intent
+	terms  []string
+}{
+	{Human, []string{"atendente", "humano", "pessoa", "falar com alguem", "operador"}},
+	{ClientArea, []string{"area do cliente", "fatura", "boleto", "segunda via", "dns", "webmail", "cpanel", "minha conta", "meu plano", "certificado", "ssl", "e-mail", "email"}},
+	{Support, []string{"suporte", "erro", "fora do ar", "nao funciona", "caiu", "problema", "lento", "ajuda"}},
+	{Sales, []string{"hospedagem", "plano", "contratar", "preco", "valor", "site", "dominio"}},
+	{TechOps, []string{"tech ops", "techops", "consultoria", "diagnostico", "seguranca", "lgpd", "arquitetura", "auditoria", "sistema", "performance"}},
+}
+
+// MatchKeywords classifica o texto por palavras-chave. É determinístico e serve de
+// alternativa quando o classificador por IA está indisponível ou inseguro.
+func MatchKeywords(text string) Classification {
+	normalized := " " + fold(text) + " "
+	for _, group := range keywords {
+		for _, term := range group.terms {
+			if strings.Contains(normalized, " "+term+" ") {
+				return Classification{Intent: group.intent, Confidence: 1}
+			}
+		}
+	}
+	return Classification{Intent: Unknown}
+}
+
+// fold remove acentos e pontuação e deixa o texto em minúsculas, com espaços simples.
+func fold(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(text)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Marca de acento separada pela decomposição NFD.
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
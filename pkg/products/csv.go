@@ -0,0 +1,112 @@
+package products
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// csvDateLayout é o formato da coluna "Date (UTC)" das exportações da Stripe.
+const csvDateLayout = "2006-01-02 15:04"
+
+// CSVSource carrega produtos das exportações CSV do painel da Stripe.
+type CSVSource struct {
+	// ProductPaths são as exportações de produtos (colunas "id", "Name", "Description"...).
+	ProductPaths []string
+	// PricesPath é a exportação de preços (colunas "Price ID", "Product ID", "Amount"...).
+	// É opcional; sem ela os produtos são carregados sem preço.
+	PricesPath string
+}
+
+// Load lê os arquivos CSV e retorna os produtos com seus preços.
+func (s CSVSource) Load() ([]Product, error) {
+	var prices map[string][]Price
+	if s.PricesPath != "" {
+		var err error
+		if prices, err = loadPricesCSV(s.PricesPath); err != nil {
+			return nil, err
+		}
+	}
+
+	var list []Product
+	for _, path := range s.ProductPaths {
+		rows, err := readCSV(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			p := Product{
+				ID:          row["id"],
+				Name:        row["Name"],
+				Description: row["Description"],
+				Type:        row["Type"],
+			}
+			if p.Type == "" {
+				p.Type = "service"
+			}
+			if created, err := time.Parse(csvDateLayout, row["Date (UTC)"]); err == nil {
+				p.CreatedAt = created
+			}
+			p.Prices = prices[p.ID]
+			list = append(list, p)
+		}
+	}
+	return list, nil
+}
+
+func loadPricesCSV(path string) (map[string][]Price, error) {
+	rows, err := readCSV(path)
+	if err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string][]Price)
+	for _, row := range rows {
+		amount, err := strconv.ParseFloat(strings.Replace(row["Amount"], ",", ".", 1), 64)
+		if err != nil {
+			return nil, fmt.Errorf("valor inválido para o preço %s em %s: %q", row["Price ID"], path, row["Amount"])
+		}
+		productID := row["Product ID"]
+		prices[productID] = append(prices[productID], Price{
+			ID:       row["Price ID"],
+			Amount:   int64(math.Round(amount * 100)),
+			Currency: strings.ToLower(row["Currency"]),
+			Interval: row["Interval"],
+		})
+	}
+	return prices, nil
+}
+
+// readCSV lê um arquivo CSV e retorna as linhas indexadas pelo nome da coluna.
+func readCSV(path string) ([]map[string]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao abrir o CSV %s: %w", path, err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler o CSV %s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	header := records[0]
+	rows := make([]map[string]string, 0, len(records)-1)
+	for _, record := range records[1:] {
+		row := make(map[string]string, len(header))
+		for i, column := range header {
+			if i < len(record) {
+				row[strings.TrimSpace(column)] = strings.TrimSpace(record[i])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
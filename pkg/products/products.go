@@ -1,43 +1,184 @@
 package products
 
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DiagnosticProductID é o produto vendido no fluxo Tech Ops.
+const DiagnosticProductID = "prod_TnrOxqyGuMF4EU"
+
 // Product representa um produto de hospedagem oferecido.
 type Product struct {
 	ID          string
 	Name        string
 	Description string
 	Type        string
+	CreatedAt   time.Time
+	Prices      []Price
+	// WHMPackage é o pacote do WHM usado no provisionamento. Apenas produtos
+	// com pacote definido são oferecidos como planos de hospedagem.
+	WHMPackage string
+}
+
+// Price representa um preço ativo de um produto.
+type Price struct {
+	ID       string
+	Amount   int64 // Em centavos
+	Currency string
+	// Interval é "month" ou "year" para preços recorrentes e vazio para pagamentos avulsos.
+	Interval string
+}
+
+// DefaultPrice retorna o primeiro preço ativo do produto.
+func (p Product) DefaultPrice() (Price, bool) {
+	if len(p.Prices) == 0 {
+		return Price{}, false
+	}
+	return p.Prices[0], true
+}
+
+// IsHostingPlan indica se o produto é um plano de hospedagem que pode ser provisionado.
+func (p Product) IsHostingPlan() bool {
+	return p.WHMPackage != "" && p.ID != DiagnosticProductID
+}
+
+// Source é uma origem de produtos para o catálogo (exportação CSV, API da Stripe etc.).
+type Source interface {
+	Load() ([]Product, error)
+}
+
+// SourceFunc adapta uma função comum à interface Source.
+type SourceFunc func() ([]Product, error)
+
+// Load chama f().
+func (f SourceFunc) Load() ([]Product, error) {
+	return f()
+}
+
+// Catalog mantém a lista de produtos em memória e permite recarregá-la em tempo de execução.
+type Catalog struct {
+	sources     []Source
+	whmPackages map[string]string
+
+	mu       sync.RWMutex
+	products []Product
+	loadedAt time.Time
 }
 
-// Lista de todos os produtos de hospedagem disponíveis.
-var AllProducts = []Product{
-	{
-		ID:          "prod_TlHnyj6Y4xiHMp",
-		Name:        "Hospedagem III",
-		Description: "Ideal para projetos robustos até 5 sites com os melhores recursos de velocidade e segurança.",
-		Type:        "service",
-	},
-	{
-		ID:          "prod_TlHnHEozuwnuQZ",
-		Name:        "Hospedagem II",
-		Description: "Ideal para você colocar até 3 sites no ar com recursos avançados de velocidade e segurança.",
-		Type:        "service",
-	},
-	{
-		ID:          "prod_TlHmZ8KTx0pe0y",
-		Name:        "Hospedagem I",
-		Description: "Ideal para colocar o seu site no ar e iniciar o seu projeto em um ambiente de qualidade.",
-		Type:        "service",
-	},
-	{
-		ID:          "prod_Tl2cU7tNegEibw",
-		Name:        "Hospedagem word press I",
-		Description: "Hospedagem I (até 1 site)Ideal para colocar seu site no ar e iniciar seu projeto com qualidade. Inclui 1 site, WordPress gerenciado, migração grátis, criador de sites, 10 GB de armazenamento, 5 emails, largura de banda ilimitada, SSL grátis, backup diário e suporte 24/7.",
-		Type:        "service",
-	},
-    {
-        ID:          "prod_TnrOxqyGuMF4EU",
-        Name:        "Diagnóstico Técnico + Jurídico",
-        Description: "Entrega: até 3 dias úteis",
-        Type:        "service",
-    },
+// NewCatalog cria um catálogo vazio. As origens são consultadas na ordem
+// informada; a primeira que responder sem erro é usada. whmPackages mapeia o
+// ID de cada produto para o pacote do WHM e tem precedência sobre o pacote
+// informado pela origem.
+func NewCatalog(whmPackages map[string]string, sources ...Source) *Catalog {
+	return &Catalog{sources: sources, whmPackages: whmPackages}
+}
+
+// Reload recarrega os produtos das origens configuradas. Se todas falharem,
+// o catálogo atual é mantido e o último erro é retornado.
+func (c *Catalog) Reload() error {
+	var lastErr error
+	for i, source := range c.sources {
+		loaded, err := source.Load()
+		if err != nil {
+			log.Printf("AVISO: Falha ao carregar produtos da origem %d do catálogo: %v", i+1, err)
+			lastErr = err
+			continue
+		}
+
+		deduped := dedupe(loaded)
+		for i := range deduped {
+			if pkg, ok := c.whmPackages[deduped[i].ID]; ok {
+				deduped[i].WHMPackage = pkg
+			}
+		}
+		c.mu.Lock()
+		c.products = deduped
+		c.loadedAt = time.Now()
+		c.mu.Unlock()
+
+		log.Printf("Catálogo recarregado com %d produtos (origem %d).", len(deduped), i+1)
+		return nil
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("nenhuma origem de produtos configurada")
+	}
+	return fmt.Errorf("falha ao recarregar o catálogo de produtos: %w", lastErr)
+}
+
+// All retorna uma cópia de todos os produtos do catálogo.
+func (c *Catalog) All() []Product {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]Product(nil), c.products...)
+}
+
+// FindByID retorna o produto com o ID informado.
+func (c *Catalog) FindByID(id string) (Product, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, p := range c.products {
+		if p.ID == id {
+			return p, true
+		}
+	}
+	return Product{}, false
+}
+
+// HostingPlans retorna os planos de hospedagem do catálogo.
+func (c *Catalog) HostingPlans() []Product {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var plans []Product
+	for _, p := range c.products {
+		if p.IsHostingPlan() {
+			plans = append(plans, p)
+		}
+	}
+	return plans
+}
+
+// LoadedAt retorna o horário da última recarga bem-sucedida.
+func (c *Catalog) LoadedAt() time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.loadedAt
+}
+
+// dedupe remove produtos repetidos com o mesmo nome (ex.: "Hospedagem III" cadastrado
+// várias vezes na Stripe), mantendo o mais recente. Produtos sem nome ou descrição,
+// como rascunhos de teste, são descartados.
+func dedupe(list []Product) []Product {
+	byName := make(map[string]Product)
+	var order []string
+	for _, p := range list {
+		p.Name = strings.Join(strings.Fields(p.Name), " ")
+		p.Description = strings.TrimSpace(p.Description)
+		if p.ID == "" || p.Name == "" || p.Description == "" {
+			continue
+		}
+
+		key := strings.ToLower(p.Name)
+		current, exists := byName[key]
+		if !exists {
+			order = append(order, key)
+		}
+		// Prefere o cadastro com preço; entre iguais, o mais recente.
+		if !exists ||
+			(len(p.Prices) > 0 && len(current.Prices) == 0) ||
+			((len(p.Prices) > 0) == (len(current.Prices) > 0) && p.CreatedAt.After(current.CreatedAt)) {
+			byName[key] = p
+		}
+	}
+
+	result := make([]Product, 0, len(order))
+	for _, key := range order {
+		result = append(result, byName[key])
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
 }
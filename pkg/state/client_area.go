@@ -0,0 +1,214 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxClientDocuments limita quantas faturas/recibos são exibidos na Área do Cliente.
+const maxClientDocuments = 5
+
+// clientAreaMenu é o menu principal da Área do Cliente, exibido após o login.
+const clientAreaMenu = "Área do Cliente 🔐\n\n" +
+	"1 - Faturas (visualização)\n" +
+	"2 - Domínios e DNS\n" +
+	"3 - Certificado SSL\n" +
+	"4 - Contas de e-mail\n" +
+	"0 - Voltar ao menu principal\n\n" +
+	"Digite o número da opção desejada."
+
+// clientDocument é uma fatura ou recibo exibido na Área do Cliente.
+type clientDocument struct {
+	Label     string
+	Status    string
+	Amount    int64
+	Currency  string
+	CreatedAt time.Time
+	URL       string
+}
+
+// handleClientArea trata a escolha de opção no menu da Área do Cliente.
+func (sm *StateManager) handleClientArea(ctx context.Context, session *UserSession, input string) string {
+	switch input {
+	case "1":
+		docs, err := sm.loadClientDocuments(ctx, session.UserID)
+		if err != nil {
+			log.Printf("ERRO: Falha ao listar faturas do usuário %s: %v", session.UserID, err)
+			return "Desculpe, não consegui consultar suas faturas agora. Por favor, tente novamente mais tarde."
+		}
+		if len(docs) == 0 {
+			return "Não encontramos faturas vinculadas ao seu cadastro.\n\n" + clientAreaMenu
+		}
+		session.State = StateClientInvoices
+		return formatClientDocuments(docs)
+	case "2":
+		session.State = StateClientDNS
+		return sm.handleClientDNS(ctx, session, "")
+	case "3":
+		return sm.handleClientSSL(ctx, session, "")
+	case "4":
+		session.State = StateClientEmail
+		return sm.handleClientEmail(ctx, session, "")
+	case "0":
+		session.State = StateInitial
+		return "Você saiu da Área do Cliente. Envie qualquer mensagem para ver o menu principal."
+	default:
+		return clientAreaMenu
+	}
+}
+
+// handleClientInvoices envia o PDF ou recibo do documento escolhido pelo cliente.
+// Nenhum link de pagamento é enviado: a Área do Cliente é apenas para visualização.
+func (sm *StateManager) handleClientInvoices(ctx context.Context, session *UserSession, input string) string {
+	if input == "0" {
+		session.State = StateClientArea
+		return clientAreaMenu
+	}
+
+	choice, err := strconv.Atoi(input)
+	if err != nil || choice < 1 || choice > maxClientDocuments {
+		return "Por favor, digite o número do documento que deseja receber ou 0 para voltar."
+	}
+
+	docs, err := sm.loadClientDocuments(ctx, session.UserID)
+	if err != nil {
+		log.Printf("ERRO: Falha ao listar faturas do usuário %s: %v", session.UserID, err)
+		return "Desculpe, não consegui consultar suas faturas agora. Por favor, tente novamente mais tarde."
+	}
+	if choice > len(docs) {
+		return "Por favor, digite o número do documento que deseja receber ou 0 para voltar."
+	}
+
+	doc := docs[choice-1]
+	session.State = StateClientArea
+	if doc.URL == "" {
+		return fmt.Sprintf("O documento %s ainda não possui PDF disponível.\n\n%s", doc.Label, clientAreaMenu)
+	}
+	return fmt.Sprintf("Aqui está o documento %s:\n%s\n\n%s", doc.Label, doc.URL, clientAreaMenu)
+}
+
+// loadClientDocuments busca as faturas e os recibos de pagamentos avulsos do cliente,
+// do mais recente para o mais antigo.
+func (sm *StateManager) loadClientDocuments(ctx context.Context, userID string) ([]clientDocument, error) {
+	customer, err := sm.dbStore.LoadCustomer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if customer.StripeCustomerID == "" {
+		return nil, nil
+	}
+
+	invoices, err := sm.stripeClient.ListInvoices(customer.StripeCustomerID, maxClientDocuments)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := sm.stripeClient.ListReceipts(customer.StripeCustomerID, maxClientDocuments)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []clientDocument
+	for _, inv := range invoices {
+		if inv.Status == "draft" {
+			continue
+		}
+		docs = append(docs, clientDocument{
+			Label:     "Fatura " + inv.Number,
+			Status:    invoiceStatusLabel(inv.Status),
+			Amount:    inv.Amount,
+			Currency:  inv.Currency,
+			CreatedAt: inv.CreatedAt,
+			URL:       inv.PDFURL,
+		})
+	}
+	// Pagamentos avulsos (ex.: Diagnóstico Tech Ops) não geram fatura, apenas recibo.
+	for _, rc := range receipts {
+		if rc.InvoiceID != "" {
+			continue
+		}
+		docs = append(docs, clientDocument{
+			Label:     "Recibo " + rc.ChargeID,
+			Status:    "Paga",
+			Amount:    rc.Amount,
+			Currency:  rc.Currency,
+			CreatedAt: rc.CreatedAt,
+			URL:       rc.ReceiptURL,
+		})
+	}
+
+	sort.Slice(docs, func(i, j int) bool { return docs[i].CreatedAt.After(docs[j].CreatedAt) })
+	if len(docs) > maxClientDocuments {
+		docs = docs[:maxClientDocuments]
+	}
+	return docs, nil
+}
+
+// LinkStripeCustomer vincula um cliente da Stripe ao cadastro do usuário.
+// É chamada pelo webhook da Stripe após a conclusão do checkout.
+func (sm *StateManager) LinkStripeCustomer(userID, stripeCustomerID, email string) error {
+	ctx := context.Background()
+
+	customer, err := sm.dbStore.LoadCustomer(ctx, userID)
+	if err != nil {
+		return err
+	}
+	set := map[string]interface{}{"stripe_customer_id": stripeCustomerID}
+	if customer.Email == "" {
+		set["email"] = email
+	}
+	return sm.dbStore.UpdateCustomer(ctx, userID, set)
+}
+
+func formatClientDocuments(docs []clientDocument) string {
+	var b strings.Builder
+	b.WriteString("Suas faturas mais recentes:\n\n")
+	for i, doc := range docs {
+		fmt.Fprintf(&b, "%d - %s (%s)\n    %s · %s\n", i+1, doc.Label, doc.CreatedAt.Format("02/01/2006"), formatAmount(doc.Amount, doc.Currency), doc.Status)
+	}
+	b.WriteString("\nDigite o número do documento para receber o PDF/recibo ou 0 para voltar.")
+	return b.String()
+}
+
+func invoiceStatusLabel(status string) string {
+	switch status {
+	case "paid":
+		return "Paga"
+	case "open":
+		return "Em aberto"
+	case "void":
+		return "Cancelada"
+	case "uncollectible":
+		return "Não cobrável"
+	default:
+		return status
+	}
+}
+
+// formatAmount formata um valor em centavos no padrão brasileiro (ex.: R$ 1.234,56).
+func formatAmount(amount int64, currency string) string {
+	symbol := strings.ToUpper(currency)
+	if symbol == "BRL" || symbol == "" {
+		symbol = "R$"
+	}
+
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	units := strconv.FormatInt(amount/100, 10)
+	var grouped strings.Builder
+	for i, r := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			grouped.WriteByte('.')
+		}
+		grouped.WriteRune(r)
+	}
+	return fmt.Sprintf("%s%s %s,%02d", sign, symbol, grouped.String(), amount%100)
+}
@@ -0,0 +1,285 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/dresbach/dresbach-assistente/pkg/whm"
+)
+
+// Ações possíveis em uma alteração de DNS.
+const (
+	dnsActionAdd    = "add"
+	dnsActionEdit   = "edit"
+	dnsActionRemove = "remove"
+)
+
+const dnsMenuOptions = "O que deseja fazer?\n" +
+	"A - Adicionar registro\n" +
+	"E - Editar registro\n" +
+	"R - Remover registro\n" +
+	"0 - Voltar à Área do Cliente"
+
+// handleClientDNS conduz o fluxo de gerenciamento de DNS da Área do Cliente.
+// Nenhuma alteração é aplicada na zona sem a confirmação explícita do cliente.
+func (sm *StateManager) handleClientDNS(ctx context.Context, session *UserSession, input string) string {
+	customer, err := sm.dbStore.LoadCustomer(ctx, session.UserID)
+	if err != nil {
+		log.Printf("ERRO: Falha ao carregar cliente %s: %v", session.UserID, err)
+		return "Desculpe, não consegui acessar seus dados agora. Por favor, tente novamente mais tarde."
+	}
+	if customer.Domain == "" {
+		session.State = StateClientArea
+		return "Não encontramos um domínio de hospedagem vinculado ao seu cadastro.\n\n" + clientAreaMenu
+	}
+	domain := customer.Domain
+	normalized := strings.ToLower(input)
+
+	switch session.State {
+	case StateClientDNSAddType:
+		recordType := strings.ToUpper(input)
+		if !isManagedRecordType(recordType) {
+			return "Tipo não suportado. Digite A, CNAME, MX ou TXT."
+		}
+		session.DNSChange.Type = recordType
+		session.State = StateClientDNSAddName
+		return "Qual o nome do registro? Use @ para o domínio principal (ex.: www, mail ou @)."
+
+	case StateClientDNSAddName:
+		if input == "" {
+			return "Por favor, digite o nome do registro (ex.: www, mail ou @)."
+		}
+		session.DNSChange.Name = normalized
+		session.State = StateClientDNSValue
+		return dnsValuePrompt(session.DNSChange.Type)
+
+	case StateClientDNSValue:
+		params, err := parseDNSValue(session.DNSChange, input)
+		if err == nil {
+			err = params.Validate(domain)
+		}
+		if err != nil {
+			return fmt.Sprintf("Valor inválido: %v.\n\n%s", err, dnsValuePrompt(session.DNSChange.Type))
+		}
+		session.DNSChange.Value = params.Value
+		session.DNSChange.Priority = params.Priority
+		session.State = StateClientDNSConfirm
+		return dnsConfirmPrompt(session.DNSChange, domain)
+
+	case StateClientDNSSelect:
+		records, err := sm.loadManagedRecords(domain)
+		if err != nil {
+			log.Printf("ERRO: Falha ao consultar a zona DNS de %s: %v", domain, err)
+			return "Desculpe, não consegui consultar seus registros DNS agora. Por favor, tente novamente mais tarde."
+		}
+		choice, err := strconv.Atoi(input)
+		if err != nil || choice < 1 || choice > len(records) {
+			return "Por favor, digite o número de um dos registros listados."
+		}
+		record := records[choice-1]
+		session.DNSChange.Line = record.Line
+		session.DNSChange.Type = record.Type
+		session.DNSChange.Name = record.Name
+		if session.DNSChange.Action == dnsActionRemove {
+			session.DNSChange.Value = record.Value()
+			session.State = StateClientDNSConfirm
+			return dnsConfirmPrompt(session.DNSChange, domain)
+		}
+		session.State = StateClientDNSValue
+		return dnsValuePrompt(record.Type)
+
+	case StateClientDNSConfirm:
+		switch normalized {
+		case "sim", "s":
+			result := sm.applyDNSChange(domain, session.DNSChange)
+			session.DNSChange = DNSChangeData{}
+			session.State = StateClientDNS
+			return result + "\n\n" + sm.dnsOverview(domain)
+		case "não", "nao", "n":
+			session.DNSChange = DNSChangeData{}
+			session.State = StateClientDNS
+			return "Alteração cancelada. Nenhum registro foi modificado.\n\n" + sm.dnsOverview(domain)
+		default:
+			return "Por favor, responda SIM para confirmar ou NÃO para cancelar."
+		}
+	}
+
+	// StateClientDNS: menu principal de DNS.
+	switch normalized {
+	case "a":
+		session.DNSChange = DNSChangeData{Action: dnsActionAdd}
+		session.State = StateClientDNSAddType
+		return "Qual o tipo do registro? (A, CNAME, MX ou TXT)"
+	case "e":
+		session.DNSChange = DNSChangeData{Action: dnsActionEdit}
+		session.State = StateClientDNSSelect
+		return "Digite o número do registro que deseja editar."
+	case "r":
+		session.DNSChange = DNSChangeData{Action: dnsActionRemove}
+		session.State = StateClientDNSSelect
+		return "Digite o número do registro que deseja remover."
+	case "0":
+		session.DNSChange = DNSChangeData{}
+		session.State = StateClientArea
+		return clientAreaMenu
+	default:
+		return sm.dnsOverview(domain)
+	}
+}
+
+// applyDNSChange aplica a alteração confirmada na zona e retorna a mensagem de resultado.
+func (sm *StateManager) applyDNSChange(domain string, change DNSChangeData) string {
+	params := whm.ZoneRecordParams{
+		Name:     change.Name,
+		Type:     change.Type,
+		Value:    change.Value,
+		Priority: change.Priority,
+	}
+
+	var err error
+	switch change.Action {
+	case dnsActionAdd:
+		err = sm.whmClient.AddZoneRecord(domain, params)
+	case dnsActionEdit, dnsActionRemove:
+		// A zona pode ter mudado desde a seleção; confere se a linha ainda é o mesmo registro.
+		if err = sm.checkRecordUnchanged(domain, change); err != nil {
+			break
+		}
+		if change.Action == dnsActionEdit {
+			err = sm.whmClient.EditZoneRecord(domain, change.Line, params)
+		} else {
+			err = sm.whmClient.RemoveZoneRecord(domain, change.Line)
+		}
+	default:
+		err = fmt.Errorf("ação de DNS desconhecida: %q", change.Action)
+	}
+
+	if err != nil {
+		log.Printf("ERRO: Falha ao aplicar alteração de DNS (%s %s %s) em %s: %v", change.Action, change.Type, change.Name, domain, err)
+		return "Desculpe, não consegui aplicar a alteração. Nenhum registro foi modificado."
+	}
+	log.Printf("DNS: alteração %s %s %s aplicada em %s", change.Action, change.Type, change.Name, domain)
+	return "Pronto! ✅ A alteração foi aplicada. A propagação pode levar algumas horas."
+}
+
+func (sm *StateManager) checkRecordUnchanged(domain string, change DNSChangeData) error {
+	records, err := sm.whmClient.DumpZone(domain)
+	if err != nil {
+		return err
+	}
+	for _, record := range records {
+		if record.Line == change.Line {
+			if record.Type == change.Type && record.Name == change.Name {
+				return nil
+			}
+			break
+		}
+	}
+	return fmt.Errorf("o registro da linha %d foi alterado desde a seleção", change.Line)
+}
+
+// loadManagedRecords retorna apenas os registros que o cliente pode gerenciar.
+func (sm *StateManager) loadManagedRecords(domain string) ([]whm.ZoneRecord, error) {
+	records, err := sm.whmClient.DumpZone(domain)
+	if err != nil {
+		return nil, err
+	}
+	var managed []whm.ZoneRecord
+	for _, record := range records {
+		if isManagedRecordType(record.Type) {
+			managed = append(managed, record)
+		}
+	}
+	return managed, nil
+}
+
+// dnsOverview lista os registros da zona seguidos do menu de DNS.
+func (sm *StateManager) dnsOverview(domain string) string {
+	records, err := sm.loadManagedRecords(domain)
+	if err != nil {
+		log.Printf("ERRO: Falha ao consultar a zona DNS de %s: %v", domain, err)
+		return "Desculpe, não consegui consultar seus registros DNS agora. Por favor, tente novamente mais tarde."
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Registros DNS de %s:\n\n", domain)
+	if len(records) == 0 {
+		b.WriteString("Nenhum registro A, CNAME, MX ou TXT encontrado.\n")
+	}
+	for i, record := range records {
+		fmt.Fprintf(&b, "%d - %s %s → %s\n", i+1, record.Type, displayRecordName(record.Name, domain), record.Value())
+	}
+	b.WriteString("\n" + dnsMenuOptions)
+	return b.String()
+}
+
+func parseDNSValue(change DNSChangeData, input string) (whm.ZoneRecordParams, error) {
+	params := whm.ZoneRecordParams{Name: change.Name, Type: change.Type, Value: input}
+	if change.Type == whm.RecordTypeMX {
+		fields := strings.Fields(input)
+		if len(fields) != 2 {
+			return params, fmt.Errorf("informe a prioridade e o servidor separados por espaço")
+		}
+		priority, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return params, fmt.Errorf("a prioridade deve ser um número")
+		}
+		params.Priority = priority
+		params.Value = strings.ToLower(fields[1])
+	}
+	if change.Type == whm.RecordTypeCNAME {
+		params.Value = strings.ToLower(input)
+	}
+	return params, nil
+}
+
+func dnsValuePrompt(recordType string) string {
+	switch recordType {
+	case whm.RecordTypeA:
+		return "Qual o endereço IPv4 de destino? (ex.: 192.0.2.10)"
+	case whm.RecordTypeCNAME:
+		return "Para qual domínio o CNAME deve apontar? (ex.: meusite.exemplo.com)"
+	case whm.RecordTypeMX:
+		return "Qual a prioridade e o servidor de e-mail? (ex.: 10 mail.exemplo.com)"
+	default:
+		return "Qual o conteúdo do registro TXT?"
+	}
+}
+
+func dnsConfirmPrompt(change DNSChangeData, domain string) string {
+	value := change.Value
+	if change.Type == whm.RecordTypeMX && change.Action != dnsActionRemove {
+		value = fmt.Sprintf("%d %s", change.Priority, change.Value)
+	}
+
+	var action string
+	switch change.Action {
+	case dnsActionAdd:
+		action = "Adicionar"
+	case dnsActionEdit:
+		action = "Editar"
+	default:
+		action = "Remover"
+	}
+	return fmt.Sprintf("Confirme a alteração:\n\n%s %s %s → %s\n\nDigite SIM para confirmar ou NÃO para cancelar.",
+		action, change.Type, displayRecordName(whm.RecordFQDN(change.Name, domain), domain), value)
+}
+
+func displayRecordName(name, domain string) string {
+	name = strings.TrimSuffix(name, ".")
+	if name == domain {
+		return "@"
+	}
+	return strings.TrimSuffix(name, "."+domain)
+}
+
+func isManagedRecordType(recordType string) bool {
+	switch recordType {
+	case whm.RecordTypeA, whm.RecordTypeCNAME, whm.RecordTypeMX, whm.RecordTypeTXT:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/domain"
+)
+
+const domainContinuePrompt = "Digite OK para continuar com o pagamento."
+
+// handleAskDomain valida o domínio informado e consulta o RDAP para decidir entre
+// registrar um domínio novo ou transferir/apontar um domínio que já existe.
+func (sm *StateManager) handleAskDomain(ctx context.Context, session *UserSession, input string) string {
+	if strings.EqualFold(strings.TrimSpace(input), "menu") {
+		return leaveSales(session)
+	}
+	name, err := domain.Normalize(input)
+	if err != nil {
+		return fmt.Sprintf("Não consegui entender esse domínio (%v).\n\nInforme o domínio completo, por exemplo: meusite.com.br", err)
+	}
+	session.Domain = name
+	session.Ownership = OwnershipData{}
+	session.Transfer = TransferData{}
+
+	status, err := sm.domains.Lookup(ctx, name)
+	if err != nil {
+		// Sem a consulta, o próprio cliente informa se o domínio já é dele.
+		log.Printf("AVISO: Falha ao consultar o RDAP do domínio %s: %v", name, err)
+		session.State = StateTechOpsCheckDomainOwner
+		return fmt.Sprintf("Não consegui verificar o domínio %s agora.\n\nEle já está registrado em seu nome?\n1 - Sim, é meu\n2 - Não, quero informar outro domínio", domain.Display(name))
+	}
+
+	if !status.Registered {
+		session.Transfer = TransferData{Register: true}
+		session.State = StateTechOpsHandleRegister
+		return fmt.Sprintf("Boa notícia! O domínio %s está disponível para registro. ✅\n\n"+
+			"Após a confirmação do pagamento, nossa equipe fará o registro em seu nome junto com a hospedagem.\n\n%s",
+			domain.Display(name), domainContinuePrompt)
+	}
+
+	session.State = StateTechOpsCheckDomainOwner
+	return fmt.Sprintf("O domínio %s já está registrado%s.\n\nEle é seu?\n1 - Sim, é meu\n2 - Não, quero informar outro domínio", domain.Display(name), holderLabel(status))
+}
+
+// handleCheckDomainOwner trata a resposta do cliente sobre a titularidade do domínio
+// registrado. Antes de hospedá-lo, o cliente comprova que controla o domínio publicando
+// um registro TXT, verificado em segundo plano por RunOwnershipChecks.
+func (sm *StateManager) handleCheckDomainOwner(ctx context.Context, session *UserSession, input string) string {
+	switch input {
+	case "2", "não", "nao":
+		session.Domain = ""
+		session.Ownership = OwnershipData{}
+		session.State = StateTechOpsAskDomain
+		return "Sem problemas! Qual domínio você deseja usar? (ex.: meusite.com.br)"
+	}
+
+	if session.Ownership.Token == "" {
+		if input != "1" && input != "sim" {
+			return "Por favor, digite 1 se o domínio é seu ou 2 para informar outro domínio."
+		}
+		token, err := domain.NewChallengeToken()
+		if err != nil {
+			log.Printf("ERRO: %v", err)
+			return "Desculpe, não consegui iniciar a verificação do domínio. Por favor, tente novamente em instantes."
+		}
+		session.Ownership = OwnershipData{Token: token, RequestedAt: time.Now()}
+		return ownershipInstructions(session)
+	}
+
+	if input != "verificar" && input != "ok" {
+		return ownershipInstructions(session)
+	}
+
+	verified, err := sm.ownership.Verify(ctx, session.Domain, session.Ownership.Token)
+	if err != nil {
+		log.Printf("AVISO: %v", err)
+	}
+	if verified {
+		return domainVerified(session)
+	}
+
+	// Reinicia a verificação automática a partir de agora.
+	session.Ownership.RequestedAt = time.Now()
+	session.Ownership.TimedOut = false
+	return fmt.Sprintf("Ainda não encontramos o registro TXT em %s. A propagação do DNS pode levar algumas horas; "+
+		"seguiremos verificando e avisaremos você por aqui assim que ele aparecer.\n\n"+
+		"Digite VERIFICAR para consultar de novo ou 2 para informar outro domínio.", domain.ChallengeName(session.Domain))
+}
+
+// ownershipInstructions explica como publicar o registro TXT do desafio.
+func ownershipInstructions(session *UserSession) string {
+	return fmt.Sprintf("Para confirmar que o domínio %s é seu, crie o registro DNS abaixo no painel onde o domínio está registrado "+
+		"(ex.: Registro.br):\n\n"+
+		"Tipo: TXT\nNome: %s\nValor: %s\n\n"+
+		"Assim que o registro aparecer no DNS, avisaremos você por aqui. "+
+		"Se preferir, digite VERIFICAR depois de criá-lo ou 2 para informar outro domínio.",
+		domain.Display(session.Domain), domain.ChallengeLabel, domain.ChallengeValue(session.Ownership.Token))
+}
+
+// domainVerified conclui o desafio e segue para a hospedagem do domínio.
+func domainVerified(session *UserSession) string {
+	session.Ownership = OwnershipData{}
+	session.Transfer = TransferData{Requested: true}
+	session.State = StateTechOpsHandleTransfer
+	return fmt.Sprintf("Domínio %s verificado! ✅\n\nVamos hospedá-lo, e ele continua registrado em seu nome.\n\n%s",
+		domain.Display(session.Domain), transferPrompt(session.Domain))
+}
+
+// registerDomainStep avisa o cliente e pede à equipe o registro de um domínio que
+// estava disponível, já apontando para os servidores DNS da hospedagem. Domínios já
+// registrados pelo cliente não têm o que fazer aqui.
+func (sm *StateManager) registerDomainStep(ctx context.Context, job *database.ProvisioningJob) error {
+	if !job.Register {
+		return nil
+	}
+	// A mensagem vai antes do pedido à equipe: se ela falhar, a etapa é refeita sem duplicar o pedido.
+	msg := fmt.Sprintf("📝 Nossa equipe vai registrar o domínio %s em seu nome. Avisaremos você por aqui assim que o registro for concluído.",
+		domain.Display(job.Domain))
+	if err := sm.whatsappClient.SendMessage(job.UserID, msg); err != nil {
+		return err
+	}
+	sm.alertOperators(ctx, "domain_registration", job.UserID, fmt.Sprintf(
+		"Registrar o domínio %s em nome do cliente (conta %s), com os servidores DNS %s",
+		job.Domain, job.Username, strings.Join(sm.nameservers, ", ")))
+	return nil
+}
+
+// holderLabel descreve o titular e a validade do registro, quando o RDAP os informa.
+func holderLabel(status *domain.Status) string {
+	label := ""
+	if status.Holder != "" {
+		label += " por " + status.Holder
+	}
+	if !status.ExpiresAt.IsZero() {
+		label += fmt.Sprintf(" (válido até %s)", status.ExpiresAt.In(saoPaulo).Format("02/01/2006"))
+	}
+	return label
+}
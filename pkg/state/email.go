@@ -0,0 +1,282 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dresbach/dresbach-assistente/pkg/credentials"
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/whm"
+)
+
+// Ações possíveis sobre uma conta de e-mail existente.
+const (
+	mailboxActionPassword = "password"
+	mailboxActionRemove   = "remove"
+)
+
+const emailMenuOptions = "O que deseja fazer?\n" +
+	"C - Criar conta de e-mail\n" +
+	"S - Gerar nova senha\n" +
+	"R - Remover conta de e-mail\n" +
+	"0 - Voltar à Área do Cliente"
+
+// mailboxLoginPattern restringe o nome da conta (antes do @) a caracteres aceitos pelo cPanel.
+var mailboxLoginPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9._-]{0,62}[a-z0-9])?$`)
+
+// handleClientEmail conduz o gerenciamento das contas de e-mail da Área do Cliente.
+// As senhas são geradas aqui e entregues somente por link de uso único.
+func (sm *StateManager) handleClientEmail(ctx context.Context, session *UserSession, input string) string {
+	customer, err := sm.dbStore.LoadCustomer(ctx, session.UserID)
+	if err != nil {
+		log.Printf("ERRO: Falha ao carregar cliente %s: %v", session.UserID, err)
+		return "Desculpe, não consegui acessar seus dados agora. Por favor, tente novamente mais tarde."
+	}
+	if customer.WHMUsername == "" || customer.Domain == "" {
+		session.State = StateClientArea
+		return "Não encontramos uma hospedagem vinculada ao seu cadastro.\n\n" + clientAreaMenu
+	}
+
+	mailboxes, err := sm.listMailboxes(customer.WHMUsername)
+	if err != nil {
+		log.Printf("ERRO: Falha ao listar os e-mails da conta %s: %v", customer.WHMUsername, err)
+		session.State = StateClientArea
+		return "Desculpe, não consegui consultar suas contas de e-mail agora. Por favor, tente novamente mais tarde."
+	}
+
+	switch session.State {
+	case StateClientEmailCreate:
+		return sm.createMailbox(ctx, session, customer, mailboxes, input)
+
+	case StateClientEmailSelect:
+		if input == "0" {
+			session.MailboxChange = MailboxChangeData{}
+			session.State = StateClientEmail
+			return sm.mailboxSummary(customer, mailboxes)
+		}
+		choice, err := strconv.Atoi(input)
+		if err != nil || choice < 1 || choice > len(mailboxes) {
+			return "Por favor, digite o número de uma das contas listadas ou 0 para voltar."
+		}
+		mailbox := mailboxes[choice-1]
+		if session.MailboxChange.Action == mailboxActionPassword {
+			return sm.resetMailboxPassword(ctx, session, customer, mailbox)
+		}
+		session.MailboxChange.Address = mailbox.Email
+		session.State = StateClientEmailConfirm
+		return fmt.Sprintf("Confirma a remoção da conta %s? Todas as mensagens dela serão apagadas.\n\n1 - Sim, remover\n2 - Não", mailbox.Email)
+
+	case StateClientEmailConfirm:
+		if input != "1" && input != "sim" {
+			session.MailboxChange = MailboxChangeData{}
+			session.State = StateClientEmail
+			return "Remoção cancelada.\n\n" + sm.mailboxSummary(customer, mailboxes)
+		}
+		mailbox, ok := findMailbox(mailboxes, session.MailboxChange.Address)
+		session.MailboxChange = MailboxChangeData{}
+		session.State = StateClientEmail
+		if !ok {
+			return "Essa conta de e-mail não existe mais.\n\n" + sm.mailboxSummary(customer, mailboxes)
+		}
+		if err := sm.whmClient.DeleteMailbox(customer.WHMUsername, mailbox); err != nil {
+			log.Printf("ERRO: Falha ao remover o e-mail %s: %v", mailbox.Email, err)
+			return "Desculpe, não consegui remover a conta de e-mail agora. Por favor, tente novamente mais tarde."
+		}
+		log.Printf("E-mail %s removido a pedido do usuário %s.", mailbox.Email, session.UserID)
+		mailboxes, _ = sm.listMailboxes(customer.WHMUsername)
+		return fmt.Sprintf("Conta %s removida. ✅\n\n%s", mailbox.Email, sm.mailboxSummary(customer, mailboxes))
+	}
+
+	// Menu de e-mails
+	session.State = StateClientEmail
+	session.MailboxChange = MailboxChangeData{}
+	switch input {
+	case "c", "criar":
+		if msg := sm.mailboxLimitReached(customer, mailboxes); msg != "" {
+			return msg + "\n\n" + emailMenuOptions
+		}
+		session.State = StateClientEmailCreate
+		return fmt.Sprintf("Qual o nome da nova conta? Digite só a parte antes do @ (ex.: contato para contato@%s).", customer.Domain)
+	case "s", "r":
+		if len(mailboxes) == 0 {
+			return "Você ainda não tem contas de e-mail.\n\n" + emailMenuOptions
+		}
+		session.MailboxChange.Action = mailboxActionPassword
+		if input == "r" {
+			session.MailboxChange.Action = mailboxActionRemove
+		}
+		session.State = StateClientEmailSelect
+		return "Digite o número da conta de e-mail:\n\n" + mailboxList(mailboxes)
+	case "0":
+		session.State = StateClientArea
+		return clientAreaMenu
+	default:
+		return sm.mailboxSummary(customer, mailboxes)
+	}
+}
+
+// createMailbox cria a conta de e-mail respeitando o limite do plano e envia a senha por link.
+func (sm *StateManager) createMailbox(ctx context.Context, session *UserSession, customer *database.Customer, mailboxes []whm.Mailbox, input string) string {
+	if input == "0" {
+		session.State = StateClientEmail
+		return sm.mailboxSummary(customer, mailboxes)
+	}
+	login := strings.TrimSuffix(input, "@"+customer.Domain)
+	if !mailboxLoginPattern.MatchString(login) || strings.Contains(login, "..") {
+		return "Nome inválido. Use letras minúsculas, números, ponto, hífen ou sublinhado (ex.: contato) ou 0 para voltar."
+	}
+	address := login + "@" + customer.Domain
+	if _, exists := findMailbox(mailboxes, address); exists {
+		return fmt.Sprintf("A conta %s já existe. Digite outro nome ou 0 para voltar.", address)
+	}
+	if msg := sm.mailboxLimitReached(customer, mailboxes); msg != "" {
+		session.State = StateClientEmail
+		return msg + "\n\n" + emailMenuOptions
+	}
+
+	password, err := credentials.GeneratePassword()
+	if err != nil {
+		log.Printf("ERRO: Falha ao gerar a senha do e-mail %s: %v", address, err)
+		return "Desculpe, não consegui criar a conta de e-mail agora. Por favor, tente novamente mais tarde."
+	}
+	if err := sm.whmClient.CreateMailbox(customer.WHMUsername, login, customer.Domain, password, sm.mailboxQuotaMB(customer)); err != nil {
+		log.Printf("ERRO: Falha ao criar o e-mail %s: %v", address, err)
+		return "Desculpe, não consegui criar a conta de e-mail agora. Por favor, tente novamente mais tarde."
+	}
+	log.Printf("E-mail %s criado a pedido do usuário %s.", address, session.UserID)
+
+	session.State = StateClientEmail
+	return fmt.Sprintf("Conta %s criada! ✅\n\n%s\n\n%s", address, sm.mailboxPasswordLink(ctx, session.UserID, address, customer.Domain, password), emailMenuOptions)
+}
+
+// resetMailboxPassword define uma nova senha para a conta de e-mail e a envia por link.
+func (sm *StateManager) resetMailboxPassword(ctx context.Context, session *UserSession, customer *database.Customer, mailbox whm.Mailbox) string {
+	session.MailboxChange = MailboxChangeData{}
+	session.State = StateClientEmail
+
+	password, err := credentials.GeneratePassword()
+	if err == nil {
+		err = sm.whmClient.ChangeMailboxPassword(customer.WHMUsername, mailbox, password)
+	}
+	if err != nil {
+		log.Printf("ERRO: Falha ao redefinir a senha do e-mail %s: %v", mailbox.Email, err)
+		return "Desculpe, não consegui gerar a nova senha agora. Por favor, tente novamente mais tarde."
+	}
+	log.Printf("Senha do e-mail %s redefinida a pedido do usuário %s.", mailbox.Email, session.UserID)
+	return fmt.Sprintf("Nova senha gerada para %s. ✅\n\n%s\n\n%s", mailbox.Email, sm.mailboxPasswordLink(ctx, session.UserID, mailbox.Email, mailbox.Domain, password), emailMenuOptions)
+}
+
+// mailboxPasswordLink emite o link de uso único com a senha da conta de e-mail.
+func (sm *StateManager) mailboxPasswordLink(ctx context.Context, userID, address, domain, password string) string {
+	link, expiresAt, err := sm.credentials.IssueMailbox(ctx, userID, address, domain, password)
+	if err != nil {
+		// A senha não é enviada pelo WhatsApp; o cliente pode gerar outra depois.
+		log.Printf("ERRO: Falha ao emitir o link de senha do e-mail %s: %v", address, err)
+		return "Não consegui gerar o link com a senha agora. Use a opção S para gerar uma nova senha em instantes."
+	}
+	return fmt.Sprintf("🔐 A senha está neste link seguro: %s\nEle só pode ser aberto uma vez e vale até %s.", link, formatDeadline(expiresAt))
+}
+
+// listMailboxes retorna as contas de e-mail da hospedagem em ordem alfabética.
+func (sm *StateManager) listMailboxes(username string) ([]whm.Mailbox, error) {
+	mailboxes, err := sm.whmClient.ListMailboxes(username)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(mailboxes, func(i, j int) bool { return mailboxes[i].Email < mailboxes[j].Email })
+	return mailboxes, nil
+}
+
+// mailboxLimit retorna o número máximo de contas de e-mail do plano do cliente e
+// se o plano tem limite.
+func (sm *StateManager) mailboxLimit(customer *database.Customer) (int, bool, error) {
+	pkg, err := sm.customerPackage(customer)
+	if err != nil {
+		return 0, false, err
+	}
+	limit, limited := whm.LimitValue(pkg.MaxEmailAccounts)
+	return limit, limited, nil
+}
+
+// mailboxLimitReached retorna a mensagem que impede a criação de uma nova conta de
+// e-mail, ou "" se o plano ainda permite criá-la. Se o pacote não puder ser
+// consultado, nenhuma conta nova é liberada.
+func (sm *StateManager) mailboxLimitReached(customer *database.Customer, mailboxes []whm.Mailbox) string {
+	limit, limited, err := sm.mailboxLimit(customer)
+	switch {
+	case err != nil:
+		log.Printf("AVISO: %v", err)
+		return "Desculpe, não consegui consultar o limite de e-mails do seu plano agora. Por favor, tente novamente mais tarde."
+	case limited && len(mailboxes) >= limit:
+		return fmt.Sprintf("Seu plano permite até %d contas de e-mail e todas já estão em uso. "+
+			"Remova uma conta ou fale com a gente para mudar de plano.", limit)
+	}
+	return ""
+}
+
+// mailboxQuotaMB divide o espaço em disco do plano entre as contas de e-mail permitidas.
+// Planos sem limite de espaço ou de contas criam caixas sem cota.
+func (sm *StateManager) mailboxQuotaMB(customer *database.Customer) int {
+	pkg, err := sm.customerPackage(customer)
+	if err != nil {
+		return 0
+	}
+	disk, limitedDisk := whm.LimitValue(pkg.DiskQuotaMB)
+	count, limitedCount := whm.LimitValue(pkg.MaxEmailAccounts)
+	if !limitedDisk || !limitedCount || count == 0 {
+		return 0
+	}
+	return disk / count
+}
+
+// customerPackage retorna o pacote do WHM do plano contratado pelo cliente.
+func (sm *StateManager) customerPackage(customer *database.Customer) (*whm.Package, error) {
+	name := customer.WHMPackage
+	if name == "" {
+		name = defaultWHMPackage
+	}
+	return sm.whmClient.FindPackage(name)
+}
+
+func findMailbox(mailboxes []whm.Mailbox, address string) (whm.Mailbox, bool) {
+	for _, mailbox := range mailboxes {
+		if strings.EqualFold(mailbox.Email, address) {
+			return mailbox, true
+		}
+	}
+	return whm.Mailbox{}, false
+}
+
+func mailboxList(mailboxes []whm.Mailbox) string {
+	var b strings.Builder
+	for i, mailbox := range mailboxes {
+		fmt.Fprintf(&b, "%d - %s\n", i+1, mailbox.Email)
+	}
+	return b.String()
+}
+
+// mailboxSummary lista as contas de e-mail e o uso do limite do plano.
+func (sm *StateManager) mailboxSummary(customer *database.Customer, mailboxes []whm.Mailbox) string {
+	limit, limited, err := sm.mailboxLimit(customer)
+	if err != nil {
+		// Sem o pacote, a lista é exibida sem o uso do limite.
+		log.Printf("AVISO: %v", err)
+	}
+	var b strings.Builder
+	b.WriteString("Contas de e-mail 📧\n\n")
+	if len(mailboxes) == 0 {
+		b.WriteString("Você ainda não tem contas de e-mail.\n")
+	} else {
+		b.WriteString(mailboxList(mailboxes))
+	}
+	if limited && limit > 0 {
+		fmt.Fprintf(&b, "\nEm uso: %d de %d contas do seu plano.\n", len(mailboxes), limit)
+	}
+	b.WriteString("\n" + emailMenuOptions)
+	return b.String()
+}
@@ -6,9 +6,15 @@ import (
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
+	"github.com/dresbach/dresbach-assistente/pkg/credentials"
 	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/domain"
+	"github.com/dresbach/dresbach-assistente/pkg/intent"
+	"github.com/dresbach/dresbach-assistente/pkg/products"
 	"github.com/dresbach/dresbach-assistente/pkg/stripe"
+	"github.com/dresbach/dresbach-assistente/pkg/techops"
 	"github.com/dresbach/dresbach-assistente/pkg/whatsapp" // Importa o pacote whatsapp
 	"github.com/dresbach/dresbach-assistente/pkg/whm"
 )
@@ -21,7 +27,23 @@ const (
 	StateInitial        State = "INITIAL"
 	StateAwaitingOption State = "AWAITING_OPTION"
 	StateClientLogin State = "CLIENT_LOGIN"
+	StateClientArea     State = "CLIENT_AREA"
+	StateClientInvoices State = "CLIENT_INVOICES"
+	StateClientDNS            State = "CLIENT_DNS"
+	StateClientDNSAddType     State = "CLIENT_DNS_ADD_TYPE"
+	StateClientDNSAddName     State = "CLIENT_DNS_ADD_NAME"
+	StateClientDNSValue       State = "CLIENT_DNS_VALUE"
+	StateClientDNSSelect      State = "CLIENT_DNS_SELECT"
+	StateClientDNSConfirm     State = "CLIENT_DNS_CONFIRM"
+	StateClientSSL            State = "CLIENT_SSL"
+	StateClientEmail          State = "CLIENT_EMAIL"
+	StateClientEmailCreate    State = "CLIENT_EMAIL_CREATE"
+	StateClientEmailSelect    State = "CLIENT_EMAIL_SELECT"
+	StateClientEmailConfirm   State = "CLIENT_EMAIL_CONFIRM"
 	StateSupport State = "SUPPORT"
+	StateSalesStart       State = "SALES_START"
+	StateSalesPlans       State = "SALES_PLANS"
+	StateSalesPlanConfirm State = "SALES_PLAN_CONFIRM"
 	StateTechOpsStart                State = "TECHOPS_START"
 	StateTechOpsLgpdConfirm          State = "TECHOPS_LGPD_CONFIRM"
 	StateTechOpsPainPoint            State = "TECHOPS_PAIN_POINT"
@@ -37,7 +59,10 @@ const (
 	StateTechOpsCheckDomainOwner State = "TECHOPS_CHECK_DOMAIN_OWNER"
 	StateTechOpsHandleTransfer   State = "TECHOPS_HANDLE_TRANSFER"
 	StateTechOpsHandleRegister   State = "TECHOPS_HANDLE_REGISTER"
-	StateAwaitingPayment State = "AWAITING_PAYMENT"
+	StateTechOpsConfirmAuthCode  State = "TECHOPS_CONFIRM_AUTH_CODE"
+	StateChoosePaymentMethod State = "CHOOSE_PAYMENT_METHOD"
+	StateAwaitingPayment     State = "AWAITING_PAYMENT"
+	StatePaymentRetry        State = "PAYMENT_RETRY"
 	StateFinalizing State = "FINALIZING"
 )
 
@@ -47,16 +72,53 @@ type StateManager struct {
 	dbStore        *database.MongoStore
 	whmClient      *whm.Client
 	stripeClient   *stripe.Client
-	whatsappClient *whatsapp.Client // Nova dependência
+	whatsappClient *whatsapp.Client
+	catalog        *products.Catalog
+	credentials    *credentials.Service
+	domains        *domain.Checker
+	ownership      *domain.Verifier
+	nameservers    []string
+	intents        intent.Classifier
+	intentPolicy   IntentPolicy
+	leads          techops.Classifier
+	// provisioningWake avisa RunProvisioningJobs de um job novo, para que ele seja
+	// executado sem esperar a próxima rodada.
+	provisioningWake chan struct{}
+}
+
+// Deps reúne as dependências do StateManager.
+type Deps struct {
+	Store        *database.MongoStore
+	WHM          *whm.Client
+	Stripe       *stripe.Client
+	WhatsApp     *whatsapp.Client
+	Catalog      *products.Catalog
+	Credentials  *credentials.Service
+	Domains      *domain.Checker
+	Ownership    *domain.Verifier
+	Nameservers  []string          // Servidores DNS para os quais os domínios hospedados devem apontar
+	Intents      intent.Classifier // Opcional: sem ele, o texto livre é classificado por palavras-chave
+	IntentPolicy IntentPolicy
+	Leads        techops.Classifier // Opcional: sem ele, os leads são classificados por palavras-chave
 }
 
 // NewManager cria um novo StateManager com todas as dependências necessárias.
-func NewManager(dbStore *database.MongoStore, whmClient *whm.Client, stripeClient *stripe.Client, whatsappClient *whatsapp.Client) *StateManager {
+func NewManager(deps Deps) *StateManager {
 	return &StateManager{
-		dbStore:        dbStore,
-		whmClient:      whmClient,
-		stripeClient:   stripeClient,
-		whatsappClient: whatsappClient, // Injeta o cliente WhatsApp
+		dbStore:        deps.Store,
+		whmClient:      deps.WHM,
+		stripeClient:   deps.Stripe,
+		whatsappClient: deps.WhatsApp,
+		catalog:        deps.Catalog,
+		credentials:    deps.Credentials,
+		domains:        deps.Domains,
+		ownership:      deps.Ownership,
+		nameservers:    deps.Nameservers,
+		intents:        deps.Intents,
+		intentPolicy:   deps.IntentPolicy,
+		leads:          deps.Leads,
+
+		provisioningWake: make(chan struct{}, 1),
 	}
 }
 
@@ -78,19 +140,94 @@ func (sm *StateManager) ProcessMessage(userID, messageText string) (string, erro
 	switch session.State {
 	// ... (casos anteriores permanecem os mesmos)
 
-	case StateTechOpsHandleTransfer, StateTechOpsHandleRegister:
-		if normalizedInput == "ok" {
-			// Gera o link de checkout da Stripe
-			checkoutURL, err := sm.stripeClient.CreateCheckoutSession(session.UserID, session.Domain)
+	case StateInitial, StateFinalizing:
+		// FINALIZING é o estado de sessões pagas antes de completePayment devolvê-las ao início.
+		response = sm.handleInitial(ctx, session, messageText)
+
+	case StateAwaitingOption:
+		response = sm.handleAwaitingOption(ctx, session, messageText)
+
+	case StateSupport:
+		response = sm.handleSupport(ctx, session, strings.TrimSpace(messageText))
+
+	case StateTechOpsStart, StateTechOpsLgpdConfirm, StateTechOpsPreAnalysisRepo, StateTechOpsPreAnalysisRepoLink,
+		StateTechOpsPreAnalysisSite, StateTechOpsPreAnalysisSiteLink, StateTechOpsPreAnalysisProblem, StateTechOpsClassification:
+		// Links e a descrição do problema são gravados como o cliente os enviou.
+		response, err = sm.handleTechOps(ctx, session, messageText)
+		if err != nil {
+			return "", err
+		}
+
+	case StateSalesStart:
+		response = sm.startPlanSelection(session)
+
+	case StateSalesPlans:
+		response = sm.handleSalesPlans(session, normalizedInput)
+
+	case StateSalesPlanConfirm:
+		response = sm.handleSalesPlanConfirm(session, normalizedInput)
+
+	case StateClientArea:
+		response = sm.handleClientArea(ctx, session, normalizedInput)
+
+	case StateClientInvoices:
+		response = sm.handleClientInvoices(ctx, session, normalizedInput)
+
+	case StateClientDNS, StateClientDNSAddType, StateClientDNSAddName, StateClientDNSValue, StateClientDNSSelect, StateClientDNSConfirm:
+		// Valores de DNS (ex.: TXT) diferenciam maiúsculas de minúsculas, então o texto original é repassado.
+		response = sm.handleClientDNS(ctx, session, strings.TrimSpace(messageText))
+
+	case StateClientSSL:
+		response = sm.handleClientSSL(ctx, session, normalizedInput)
+
+	case StateClientEmail, StateClientEmailCreate, StateClientEmailSelect, StateClientEmailConfirm:
+		response = sm.handleClientEmail(ctx, session, normalizedInput)
+
+	case StateTechOpsAskDomain:
+		response = sm.handleAskDomain(ctx, session, messageText)
+
+	case StateTechOpsCheckDomainOwner:
+		response = sm.handleCheckDomainOwner(ctx, session, normalizedInput)
+
+	case StateTechOpsHandleTransfer:
+		// O código EPP diferencia maiúsculas de minúsculas, então o texto original é repassado.
+		response, err = sm.handleTransfer(ctx, session, strings.TrimSpace(messageText))
+		if err != nil {
+			return "", err
+		}
+
+	case StateTechOpsConfirmAuthCode:
+		response, err = sm.handleConfirmAuthCode(ctx, session, strings.TrimSpace(messageText))
+		if err != nil {
+			return "", err
+		}
+
+	case StateTechOpsHandleRegister:
+		if normalizedInput == "menu" {
+			session.Transfer = TransferData{}
+			response = leaveSales(session)
+		} else if normalizedInput == "ok" {
+			response, err = sm.choosePaymentMethod(ctx, session)
 			if err != nil {
-				log.Printf("ERRO: Falha ao criar a sessão de checkout da Stripe: %v", err)
-				response = "Desculpe, não consegui gerar o link de pagamento. Por favor, tente novamente mais tarde."
-			} else {
-				response = fmt.Sprintf("Tudo pronto! Para finalizar, efetue o pagamento através deste link seguro: %s", checkoutURL)
-				session.State = StateAwaitingPayment // Muda o estado para aguardar a confirmação do pagamento
+				return "", err
 			}
 		} else {
-			response = "Por favor, digite 'OK' para confirmar e continuar."
+			response = "Por favor, digite 'OK' para confirmar e continuar ou 'MENU' para voltar."
+		}
+
+	case StateChoosePaymentMethod:
+		response, err = sm.handleChoosePaymentMethod(ctx, session, normalizedInput)
+		if err != nil {
+			return "", err
+		}
+
+	case StateAwaitingPayment:
+		response = sm.handleAwaitingPayment(session, normalizedInput)
+
+	case StatePaymentRetry:
+		response, err = sm.handlePaymentRetry(ctx, session, normalizedInput)
+		if err != nil {
+			return "", err
 		}
 
 	// ... (outros casos)
@@ -111,55 +248,73 @@ func (sm *StateManager) ProcessMessage(userID, messageText string) (string, erro
 	return response, nil
 }
 
-// ProvisionAccount é a função que será chamada pelo webhook da Stripe.
-func (sm *StateManager) ProvisionAccount(userID, domain, contactEmail string) error {
-	password := "senhaSuperSegura123!"
-
-	params := whm.CreateAccountParams{
-		Username:     generateUsername(domain),
-		Domain:       domain,
-		Plan:         "Dresbach-Start",
-		Password:     password,
-		ContactEmail: contactEmail,
-	}
+// defaultWHMPackage é usado em pagamentos antigos, feitos antes de o produto ser registrado nos metadados.
+const defaultWHMPackage = "Dresbach-Start"
 
-	_, err := sm.whmClient.CreateAccount(params)
-	if err != nil {
-		log.Printf("ERRO AO PROVISIONAR CONTA: Falha ao criar conta no WHM para o usuário %s: %v", userID, err)
-		return fmt.Errorf("falha ao criar conta no WHM: %w", err)
+// ProvisionAccount é a função que será chamada pelo webhook da Stripe. Ela apenas
+// grava o job de provisionamento; a conta é criada por RunProvisioningJobs.
+func (sm *StateManager) ProvisionAccount(userID, domain, contactEmail, productID string) error {
+	ctx := context.Background()
+	// O pagamento já foi confirmado: a conversa sai da espera de pagamento.
+	sm.completePayment(ctx, userID)
+
+	plan := defaultWHMPackage
+	if productID != "" {
+		product, ok := sm.catalog.FindByID(productID)
+		if !ok {
+			return fmt.Errorf("produto %s não encontrado no catálogo", productID)
+		}
+		if !product.IsHostingPlan() {
+			// Produtos que não são hospedagem (ex.: Diagnóstico Tech Ops) não criam conta no WHM.
+			log.Printf("Pagamento do produto %s confirmado para o usuário %s; nenhuma conta a provisionar.", product.Name, userID)
+			if product.ID == products.DiagnosticProductID {
+				sm.scheduleDiagnostic(ctx, userID)
+			}
+			msg := fmt.Sprintf("Ótima notícia! ✅\n\nSeu pagamento do %s foi confirmado. Nossa equipe entrará em contato para os próximos passos.", product.Name)
+			if err := sm.whatsappClient.SendMessage(userID, msg); err != nil {
+				log.Printf("AVISO: Falha ao enviar confirmação de pagamento para o usuário %s: %v", userID, err)
+			}
+			return nil
+		}
+		plan = product.WHMPackage
 	}
 
-	log.Printf("SUCESSO: Conta para o domínio %s (usuário %s) provisionada com sucesso!", domain, userID)
-
-	// Envia a confirmação para o cliente via WhatsApp
-	msg := fmt.Sprintf("Ótima notícia! ✅\n\nSeu pagamento foi confirmado e sua conta para o domínio `%s` foi criada com sucesso!\n\nEm breve você receberá seus dados de acesso.", domain)
-	if err := sm.whatsappClient.SendMessage(userID, msg); err != nil {
-		log.Printf("AVISO: Provisionamento bem-sucedido, mas falha ao enviar notificação de confirmação para o usuário %s: %v", userID, err)
-		// Não retorna erro aqui, pois o provisionamento principal já ocorreu.
+	// A conta é criada por um job em etapas, persistido antes da primeira tentativa
+	// para que nenhuma falha deixe o pagamento sem conta.
+	job := newProvisioningJob(userID, domain, contactEmail, productID)
+	job.Plan = plan
+	sm.attachTransfer(ctx, job)
+	if err := sm.dbStore.EnqueueProvisioningJob(ctx, job); err != nil {
+		return err
 	}
+	sm.clearSessionTransfer(ctx, userID, domain)
+	// O job é executado por RunProvisioningJobs, fora do webhook, que responde à
+	// Stripe assim que o job está gravado.
+	sm.wakeProvisioning()
 
+	sm.notify(userID, "Seu pagamento foi confirmado! ✅\n\n"+
+		"Estamos criando a sua conta e avisaremos você por aqui assim que estiver pronta.")
 	return nil
 }
 
-// generateUsername cria um nome de usuário de 8 caracteres a partir do domínio para evitar colisões.
-func generateUsername(domain string) string {
-	// Remove tld, www, etc.
-	username := strings.Split(domain, ".")[0]
-	if len(username) > 8 {
-		return username[:8]
-	}
-	return username
-}
 
 // --- Funções auxiliares e Structs ---
 
 // ... (structs e funções auxiliares permanecem as mesmas)
 
 type UserSession struct {
-	UserID      string
-	State       State
-	Domain      string // Campo para armazenar o domínio
-	PreAnalysis PreAnalysisData
+	UserID        string
+	State         State
+	Domain        string // Campo para armazenar o domínio
+	ProductID     string // Produto escolhido para o checkout
+	PreAnalysis   PreAnalysisData
+	DNSChange     DNSChangeData     // Alteração de DNS pendente de confirmação
+	Payment       PaymentData       // Cobrança aguardando pagamento
+	Ownership     OwnershipData     // Desafio de titularidade do domínio
+	Transfer      TransferData      // Domínio já registrado que será hospedado conosco
+	MailboxChange MailboxChangeData // Alteração de conta de e-mail em andamento
+	PendingIntent string            // Opção do menu sugerida ao cliente, aguardando confirmação
+	TechOps       TechOpsData       // Classificação do lead Tech Ops
 }
 
 type PreAnalysisData struct {
@@ -168,28 +323,88 @@ type PreAnalysisData struct {
 	ProblemDescription string
 }
 
+type TechOpsData struct {
+	Category     string
+	Urgency      string
+	Complexity   string
+	Summary      string
+	Source       string // "ai" ou "keywords"
+	ClassifiedAt time.Time
+}
+
+type DNSChangeData struct {
+	Action   string
+	Line     int
+	Type     string
+	Name     string
+	Value    string
+	Priority int
+}
+
+type OwnershipData struct {
+	Token       string
+	RequestedAt time.Time
+	TimedOut    bool
+}
+
+type TransferData struct {
+	Register       bool
+	Requested      bool
+	SealedAuthCode []byte // Código EPP cifrado pelo serviço de credenciais
+}
+
+type MailboxChangeData struct {
+	Action  string
+	Address string
+}
+
+type PaymentData struct {
+	Reference   string
+	Method      string
+	URL         string
+	RequestedAt time.Time
+	ExpiresAt   time.Time
+	Reminders   int
+}
+
 func copyToDBSession(session *UserSession) *database.Session {
 	return &database.Session{
-		UserID: session.UserID,
-		State:  string(session.State),
-		Domain: session.Domain,
+		UserID:    session.UserID,
+		State:     string(session.State),
+		Domain:    session.Domain,
+		ProductID: session.ProductID,
 		PreAnalysis: database.PreAnalysisData{
 			RepoURL:            session.PreAnalysis.RepoURL,
 			SystemURL:          session.PreAnalysis.SystemURL,
 			ProblemDescription: session.PreAnalysis.ProblemDescription,
 		},
+		DNSChange:     database.DNSChangeData(session.DNSChange),
+		Payment:       database.PaymentData(session.Payment),
+		Ownership:     database.OwnershipData(session.Ownership),
+		Transfer:      database.TransferData(session.Transfer),
+		MailboxChange: database.MailboxChangeData(session.MailboxChange),
+		PendingIntent: session.PendingIntent,
+		TechOps:       database.TechOpsData(session.TechOps),
 	}
 }
 
 func copyFromDBSession(dbSession *database.Session) *UserSession {
 	return &UserSession{
-		UserID: dbSession.UserID,
-		State:  State(dbSession.State),
-		Domain: dbSession.Domain,
+		UserID:    dbSession.UserID,
+		State:     State(dbSession.State),
+		Domain:    dbSession.Domain,
+		ProductID: dbSession.ProductID,
 		PreAnalysis: PreAnalysisData{
 			RepoURL:            dbSession.PreAnalysis.RepoURL,
 			SystemURL:          dbSession.PreAnalysis.SystemURL,
 			ProblemDescription: dbSession.PreAnalysis.ProblemDescription,
 		},
+		DNSChange:     DNSChangeData(dbSession.DNSChange),
+		Payment:       PaymentData(dbSession.Payment),
+		Ownership:     OwnershipData(dbSession.Ownership),
+		Transfer:      TransferData(dbSession.Transfer),
+		MailboxChange: MailboxChangeData(dbSession.MailboxChange),
+		PendingIntent: dbSession.PendingIntent,
+		TechOps:       TechOpsData(dbSession.TechOps),
 	}
 }
@@ -0,0 +1,173 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/dresbach/dresbach-assistente/pkg/intent"
+)
+
+// IntentPolicy define os limites de confiança da classificação do texto livre.
+type IntentPolicy struct {
+	// Accept é a confiança mínima para seguir direto para a opção identificada.
+	Accept float64
+	// Confirm é a confiança mínima para sugerir a opção e pedir confirmação ao cliente.
+	// Abaixo dela, vale a classificação por palavras-chave.
+	Confirm float64
+}
+
+const mainMenu = "Olá! 👋 Sou o assistente da Dresbach Hosting. Como posso ajudar?\n\n" +
+	"1 - Área do Cliente\n" +
+	"2 - Planos de hospedagem\n" +
+	"3 - Tech Ops (consultoria e diagnóstico)\n" +
+	"4 - Suporte técnico\n" +
+	"5 - Falar com um atendente\n\n" +
+	"Digite o número da opção ou escreva o que você precisa."
+
+// menuOptions relaciona as opções numeradas do menu principal às intenções.
+var menuOptions = map[string]intent.Intent{
+	"1": intent.ClientArea,
+	"2": intent.Sales,
+	"3": intent.TechOps,
+	"4": intent.Support,
+	"5": intent.Human,
+}
+
+// intentLabels descreve as intenções ao pedir confirmação ao cliente.
+var intentLabels = map[intent.Intent]string{
+	intent.TechOps:    "consultoria e diagnóstico Tech Ops",
+	intent.ClientArea: "a Área do Cliente",
+	intent.Support:    "suporte técnico",
+	intent.Sales:      "planos de hospedagem",
+	intent.Human:      "falar com um atendente",
+}
+
+// greetings são mensagens que apenas abrem a conversa e levam ao menu.
+var greetings = map[string]bool{
+	"oi": true, "ola": true, "olá": true, "bom dia": true, "boa tarde": true, "boa noite": true, "menu": true, "inicio": true, "início": true,
+}
+
+// handleInitial recebe a primeira mensagem da conversa. Um pedido claro segue
+// direto para a opção; saudações e pedidos vagos recebem o menu principal.
+func (sm *StateManager) handleInitial(ctx context.Context, session *UserSession, text string) string {
+	input := strings.ToLower(strings.TrimSpace(text))
+	session.State = StateAwaitingOption
+	session.PendingIntent = ""
+	if input == "" || greetings[strings.Trim(input, "!.,? ")] {
+		return mainMenu
+	}
+
+	if choice, ok := menuOptions[input]; ok {
+		return sm.routeIntent(ctx, session, choice, text)
+	}
+	if c := sm.classifyIntent(ctx, text); c.Intent != intent.Unknown && c.Confidence >= sm.intentPolicy.Accept {
+		return sm.routeIntent(ctx, session, c.Intent, text)
+	}
+	return mainMenu
+}
+
+// handleAwaitingOption trata a escolha no menu principal, por número ou texto livre.
+func (sm *StateManager) handleAwaitingOption(ctx context.Context, session *UserSession, text string) string {
+	input := strings.ToLower(strings.TrimSpace(text))
+
+	pending := intent.Intent(session.PendingIntent)
+	session.PendingIntent = ""
+	if pending != "" && (input == "sim" || input == "s") {
+		return sm.routeIntent(ctx, session, pending, text)
+	}
+
+	if choice, ok := menuOptions[input]; ok {
+		return sm.routeIntent(ctx, session, choice, text)
+	}
+
+	c := sm.classifyIntent(ctx, text)
+	switch {
+	case c.Intent == intent.Unknown:
+		return "Desculpe, não entendi. 🙂\n\n" + mainMenu
+	case c.Confidence >= sm.intentPolicy.Accept:
+		return sm.routeIntent(ctx, session, c.Intent, text)
+	default:
+		session.PendingIntent = string(c.Intent)
+		return fmt.Sprintf("Você quer %s? Digite SIM para confirmar ou o número de uma opção do menu:\n\n%s",
+			intentLabels[c.Intent], mainMenu)
+	}
+}
+
+// classifyIntent classifica o texto livre pelo classificador configurado. Sem ele,
+// com erro ou com confiança abaixo de IntentPolicy.Confirm, usa as palavras-chave.
+func (sm *StateManager) classifyIntent(ctx context.Context, text string) intent.Classification {
+	if sm.intents != nil {
+		c, err := sm.intents.Classify(ctx, text)
+		switch {
+		case err != nil:
+			log.Printf("AVISO: Falha ao classificar a mensagem: %v", err)
+		case c.Intent != intent.Unknown && c.Confidence >= sm.intentPolicy.Confirm:
+			return c
+		}
+	}
+	return intent.MatchKeywords(text)
+}
+
+// routeIntent leva a conversa para a opção escolhida.
+func (sm *StateManager) routeIntent(ctx context.Context, session *UserSession, choice intent.Intent, text string) string {
+	session.PendingIntent = ""
+	log.Printf("Usuário %s direcionado para %s.", session.UserID, choice)
+
+	switch choice {
+	case intent.TechOps:
+		return sm.startTechOps(session)
+
+	case intent.ClientArea:
+		customer, err := sm.dbStore.LoadCustomer(ctx, session.UserID)
+		if err != nil {
+			log.Printf("ERRO: Falha ao carregar cliente %s: %v", session.UserID, err)
+			return "Desculpe, não consegui acessar seus dados agora. Por favor, tente novamente mais tarde."
+		}
+		// A Área do Cliente é liberada para o número vinculado a uma compra.
+		if customer.WHMUsername == "" && customer.StripeCustomerID == "" {
+			session.State = StateAwaitingOption
+			return "Não encontramos um cadastro de cliente vinculado a este número. " +
+				"Se você já é cliente, digite 5 para falar com um atendente.\n\n" + mainMenu
+		}
+		session.State = StateClientArea
+		return clientAreaMenu
+
+	case intent.Support:
+		session.State = StateSupport
+		return "Certo! Descreva em uma mensagem o problema que você está enfrentando e, se possível, o domínio afetado."
+
+	case intent.Sales:
+		customer, err := sm.dbStore.LoadCustomer(ctx, session.UserID)
+		if err != nil {
+			log.Printf("ERRO: Falha ao carregar cliente %s: %v", session.UserID, err)
+			return "Desculpe, não consegui acessar seus dados agora. Por favor, tente novamente mais tarde."
+		}
+		// O cadastro guarda uma única hospedagem por número.
+		if hasActiveHosting(customer) {
+			session.State = StateAwaitingOption
+			return fmt.Sprintf("Você já tem uma hospedagem ativa para o domínio %s. Para trocar de plano ou contratar "+
+				"uma segunda hospedagem, digite 5 para falar com um atendente.\n\n%s", customer.Domain, mainMenu)
+		}
+		return sm.startPlanSelection(session)
+
+	case intent.Human:
+		sm.alertOperators(ctx, "human_handoff", session.UserID, fmt.Sprintf("Cliente pediu atendimento humano: %q", text))
+		session.State = StateInitial
+		return "Tudo bem! Um atendente vai falar com você por aqui em breve. 🙂"
+	}
+
+	session.State = StateAwaitingOption
+	return mainMenu
+}
+
+// handleSupport registra o problema descrito pelo cliente na fila da equipe.
+func (sm *StateManager) handleSupport(ctx context.Context, session *UserSession, text string) string {
+	if strings.TrimSpace(text) == "" {
+		return "Por favor, descreva o problema em uma mensagem."
+	}
+	sm.alertOperators(ctx, "support", session.UserID, text)
+	session.State = StateInitial
+	return "Recebemos sua solicitação! ✅ Nossa equipe de suporte vai analisar e responder por aqui."
+}
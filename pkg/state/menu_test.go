@@ -0,0 +1,178 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dresbach/dresbach-assistente/pkg/intent"
+)
+
+// As mensagens dos testes levam às opções Tech Ops e Suporte, que não consultam o MongoDB.
+const (
+	textTechOps = "quero revisar a infraestrutura da empresa"
+	textSupport = "minha loja parou de abrir"
+)
+
+func newMenuManager(classifier intent.Classifier) *StateManager {
+	return &StateManager{
+		intents:      classifier,
+		intentPolicy: IntentPolicy{Accept: 0.8, Confirm: 0.5},
+	}
+}
+
+func TestHandleInitial(t *testing.T) {
+	tests := []struct {
+		name       string
+		text       string
+		confidence float64
+		wantState  State
+	}{
+		{name: "saudação", text: "Oi!", wantState: StateAwaitingOption},
+		{name: "número do menu", text: "3", wantState: StateTechOpsStart},
+		{name: "confiança no limite de aceite", text: textTechOps, confidence: 0.8, wantState: StateTechOpsStart},
+		// Na primeira mensagem não há confirmação: abaixo do aceite, o cliente recebe o menu.
+		{name: "confiança abaixo do aceite", text: textTechOps, confidence: 0.79, wantState: StateAwaitingOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := newMenuManager(&intent.Fake{Responses: map[string]intent.Classification{
+				textTechOps: {Intent: intent.TechOps, Confidence: tt.confidence},
+			}})
+			session := &UserSession{UserID: "5511999999999", State: StateInitial, PendingIntent: string(intent.Support)}
+
+			sm.handleInitial(context.Background(), session, tt.text)
+			if session.State != tt.wantState {
+				t.Errorf("estado = %s, esperava %s", session.State, tt.wantState)
+			}
+			if session.PendingIntent != "" {
+				t.Errorf("PendingIntent = %q, esperava vazio", session.PendingIntent)
+			}
+		})
+	}
+}
+
+func TestHandleAwaitingOption(t *testing.T) {
+	tests := []struct {
+		name        string
+		text        string
+		response    intent.Classification
+		err         error
+		wantState   State
+		wantPending intent.Intent
+		wantReply   string
+	}{
+		{
+			name:      "confiança no limite de aceite",
+			text:      textTechOps,
+			response:  intent.Classification{Intent: intent.TechOps, Confidence: 0.8},
+			wantState: StateTechOpsStart,
+		},
+		{
+			name:        "confiança entre confirmação e aceite",
+			text:        textTechOps,
+			response:    intent.Classification{Intent: intent.TechOps, Confidence: 0.79},
+			wantState:   StateAwaitingOption,
+			wantPending: intent.TechOps,
+			wantReply:   "Digite SIM para confirmar",
+		},
+		{
+			name:        "confiança no limite de confirmação",
+			text:        textSupport,
+			response:    intent.Classification{Intent: intent.Support, Confidence: 0.5},
+			wantState:   StateAwaitingOption,
+			wantPending: intent.Support,
+		},
+		{
+			name:      "confiança abaixo da confirmação sem palavra-chave",
+			text:      textSupport,
+			response:  intent.Classification{Intent: intent.Support, Confidence: 0.49},
+			wantState: StateAwaitingOption,
+			wantReply: "não entendi",
+		},
+		{
+			name:      "confiança abaixo da confirmação usa palavras-chave",
+			text:      "preciso de suporte",
+			response:  intent.Classification{Intent: intent.TechOps, Confidence: 0.3},
+			wantState: StateSupport,
+		},
+		{
+			name:      "erro do classificador usa palavras-chave",
+			text:      "quero uma consultoria",
+			err:       errors.New("classificador indisponível"),
+			wantState: StateTechOpsStart,
+		},
+		{
+			name:      "erro do classificador sem palavra-chave",
+			text:      textTechOps,
+			err:       errors.New("classificador indisponível"),
+			wantState: StateAwaitingOption,
+			wantReply: "não entendi",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &intent.Fake{
+				Responses: map[string]intent.Classification{tt.text: tt.response},
+				Err:       tt.err,
+			}
+			sm := newMenuManager(fake)
+			session := &UserSession{UserID: "5511999999999", State: StateAwaitingOption}
+
+			reply := sm.handleAwaitingOption(context.Background(), session, tt.text)
+			if session.State != tt.wantState {
+				t.Errorf("estado = %s, esperava %s", session.State, tt.wantState)
+			}
+			if session.PendingIntent != string(tt.wantPending) {
+				t.Errorf("PendingIntent = %q, esperava %q", session.PendingIntent, tt.wantPending)
+			}
+			if !strings.Contains(reply, tt.wantReply) {
+				t.Errorf("resposta %q não contém %q", reply, tt.wantReply)
+			}
+			if len(fake.Calls) != 1 || fake.Calls[0] != tt.text {
+				t.Errorf("classificador chamado com %q, esperava %q", fake.Calls, tt.text)
+			}
+		})
+	}
+}
+
+func TestHandleAwaitingOptionConfirmation(t *testing.T) {
+	fake := &intent.Fake{Responses: map[string]intent.Classification{
+		textSupport: {Intent: intent.Support, Confidence: 0.6},
+	}}
+	sm := newMenuManager(fake)
+	session := &UserSession{UserID: "5511999999999", State: StateAwaitingOption}
+
+	sm.handleAwaitingOption(context.Background(), session, textSupport)
+	if session.PendingIntent != string(intent.Support) {
+		t.Fatalf("PendingIntent = %q, esperava %q", session.PendingIntent, intent.Support)
+	}
+
+	sm.handleAwaitingOption(context.Background(), session, " SIM ")
+	if session.State != StateSupport {
+		t.Errorf("estado = %s, esperava %s", session.State, StateSupport)
+	}
+	if session.PendingIntent != "" {
+		t.Errorf("PendingIntent = %q, esperava vazio", session.PendingIntent)
+	}
+	if len(fake.Calls) != 1 {
+		t.Errorf("a confirmação não deveria passar pelo classificador: %q", fake.Calls)
+	}
+}
+
+func TestHandleAwaitingOptionDiscardsPendingIntent(t *testing.T) {
+	sm := newMenuManager(&intent.Fake{})
+	session := &UserSession{UserID: "5511999999999", State: StateAwaitingOption, PendingIntent: string(intent.TechOps)}
+
+	// Qualquer resposta que não seja SIM descarta a sugestão anterior.
+	sm.handleAwaitingOption(context.Background(), session, "não sei")
+	if session.PendingIntent != "" {
+		t.Errorf("PendingIntent = %q, esperava vazio", session.PendingIntent)
+	}
+
+	sm.handleAwaitingOption(context.Background(), session, "sim")
+	if session.State != StateAwaitingOption {
+		t.Errorf("estado = %s, esperava %s", session.State, StateAwaitingOption)
+	}
+}
@@ -0,0 +1,106 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/domain"
+)
+
+// OwnershipPolicy define a verificação automática dos desafios de titularidade.
+type OwnershipPolicy struct {
+	// Interval é o intervalo entre as consultas ao DNS.
+	Interval time.Duration
+	// Timeout é por quanto tempo o registro TXT é procurado antes de orientar o cliente.
+	Timeout time.Duration
+}
+
+// RunOwnershipChecks verifica periodicamente os desafios de titularidade pendentes
+// até que o contexto seja cancelado.
+func (sm *StateManager) RunOwnershipChecks(ctx context.Context, policy OwnershipPolicy) {
+	ticker := time.NewTicker(policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sm.checkOwnershipChallenges(ctx, policy)
+		}
+	}
+}
+
+// checkOwnershipChallenges avança as sessões cujo registro TXT já foi publicado e
+// orienta os clientes cujo prazo de verificação terminou.
+func (sm *StateManager) checkOwnershipChallenges(ctx context.Context, policy OwnershipPolicy) {
+	dbSessions, err := sm.dbStore.FindSessionsByState(ctx, string(StateTechOpsCheckDomainOwner))
+	if err != nil {
+		log.Printf("ERRO: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range dbSessions {
+		session := copyFromDBSession(&dbSessions[i])
+		if session.Ownership.Token == "" || session.Ownership.TimedOut {
+			continue
+		}
+
+		verified, err := sm.ownership.Verify(ctx, session.Domain, session.Ownership.Token)
+		if err != nil {
+			log.Printf("AVISO: %v", err)
+		}
+
+		// Só os campos do desafio são gravados, e apenas se o cliente não tiver mudado
+		// de estado, de domínio ou reiniciado a verificação desde a consulta.
+		match := map[string]interface{}{
+			"state":                  string(StateTechOpsCheckDomainOwner),
+			"domain":                 session.Domain,
+			"ownership.token":        session.Ownership.Token,
+			"ownership.requested_at": session.Ownership.RequestedAt,
+		}
+		var msg string
+		var set map[string]interface{}
+		switch {
+		case verified:
+			msg = domainVerified(session)
+			set = map[string]interface{}{
+				"state":     string(session.State),
+				"ownership": database.OwnershipData(session.Ownership),
+				"transfer":  database.TransferData(session.Transfer),
+			}
+		case now.Sub(session.Ownership.RequestedAt) >= policy.Timeout:
+			session.Ownership.TimedOut = true
+			msg = ownershipTimeoutMessage(session)
+			set = map[string]interface{}{"ownership.timed_out": true}
+		default:
+			continue
+		}
+
+		updated, err := sm.dbStore.UpdateSession(ctx, session.UserID, match, set)
+		if err != nil {
+			log.Printf("AVISO: Falha ao atualizar a verificação do domínio do usuário %s: %v", session.UserID, err)
+			continue
+		}
+		if !updated {
+			continue
+		}
+		sm.notify(session.UserID, msg)
+	}
+}
+
+// ownershipTimeoutMessage orienta o cliente quando o registro TXT não apareceu no prazo.
+func ownershipTimeoutMessage(session *UserSession) string {
+	return fmt.Sprintf("Ainda não encontramos o registro TXT que confirma o domínio %s. 🔎\n\n"+
+		"Confira no painel do seu domínio se:\n"+
+		"• o tipo é TXT e o nome é %s (alguns painéis pedem o nome completo: %s);\n"+
+		"• o valor é exatamente %s;\n"+
+		"• o domínio usa os servidores DNS desse painel.\n\n"+
+		"Depois de corrigir, digite VERIFICAR para tentar novamente ou 2 para informar outro domínio.",
+		domain.Display(session.Domain), domain.ChallengeLabel, domain.ChallengeName(session.Domain),
+		domain.ChallengeValue(session.Ownership.Token))
+}
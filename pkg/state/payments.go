@@ -0,0 +1,250 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/products"
+	"github.com/dresbach/dresbach-assistente/pkg/stripe"
+)
+
+// saoPaulo é o fuso usado para exibir prazos de pagamento aos clientes.
+var saoPaulo = time.FixedZone("America/Sao_Paulo", -3*60*60)
+
+// Formas de pagamento registradas na cobrança pendente da sessão.
+const (
+	paymentMethodCheckout = "checkout"
+	paymentMethodPix      = "pix"
+	paymentMethodBoleto   = "boleto"
+)
+
+const paymentErrorMessage = "Desculpe, não consegui gerar o link de pagamento. Por favor, tente novamente mais tarde."
+
+const paymentMethodPrompt = "Como você prefere pagar?\n\n1 - Pix (QR Code aqui mesmo no WhatsApp)\n2 - Cartão ou boleto"
+
+// checkoutParams monta os dados de cobrança do produto da sessão.
+func (sm *StateManager) checkoutParams(ctx context.Context, session *UserSession) (stripe.CheckoutParams, bool, error) {
+	// Reaproveita o cliente da Stripe caso o usuário já tenha comprado antes
+	customer, err := sm.dbStore.LoadCustomer(ctx, session.UserID)
+	if err != nil {
+		return stripe.CheckoutParams{}, false, fmt.Errorf("falha ao carregar cliente no StateManager: %w", err)
+	}
+
+	// Sem produto escolhido, o fluxo Tech Ops cobra o diagnóstico.
+	productID := session.ProductID
+	if productID == "" {
+		productID = products.DiagnosticProductID
+	}
+	product, ok := sm.catalog.FindByID(productID)
+	if !ok {
+		log.Printf("ERRO: Produto %s não encontrado no catálogo", productID)
+		return stripe.CheckoutParams{}, false, nil
+	}
+
+	return stripe.CheckoutParams{
+		UserID:     session.UserID,
+		Domain:     session.Domain,
+		CustomerID: customer.StripeCustomerID,
+		Product:    product,
+	}, true, nil
+}
+
+// choosePaymentMethod pergunta a forma de pagamento. Pix só é oferecido para
+// produtos avulsos, como o diagnóstico Tech Ops; assinaturas vão direto ao checkout.
+func (sm *StateManager) choosePaymentMethod(ctx context.Context, session *UserSession) (string, error) {
+	params, ok, err := sm.checkoutParams(ctx, session)
+	if err != nil || !ok {
+		return paymentErrorMessage, err
+	}
+	if price, hasPrice := params.Product.DefaultPrice(); hasPrice && price.Interval != "" {
+		return sm.startCheckout(ctx, session)
+	}
+
+	session.State = StateChoosePaymentMethod
+	return paymentMethodPrompt, nil
+}
+
+// handleChoosePaymentMethod trata a escolha entre Pix e o checkout da Stripe.
+func (sm *StateManager) handleChoosePaymentMethod(ctx context.Context, session *UserSession, input string) (string, error) {
+	switch input {
+	case "1", "pix":
+		return sm.startPixPayment(ctx, session)
+	case "2", "cartão", "cartao", "boleto":
+		return sm.startCheckout(ctx, session)
+	default:
+		return "Por favor, digite 1 para Pix ou 2 para cartão ou boleto.", nil
+	}
+}
+
+// startPixPayment cria a cobrança Pix e envia o QR Code e o código copia e cola.
+func (sm *StateManager) startPixPayment(ctx context.Context, session *UserSession) (string, error) {
+	params, ok, err := sm.checkoutParams(ctx, session)
+	if err != nil || !ok {
+		return paymentErrorMessage, err
+	}
+
+	charge, err := sm.stripeClient.CreatePixPayment(params)
+	if err != nil {
+		log.Printf("ERRO: Falha ao criar a cobrança Pix: %v", err)
+		return "Desculpe, não consegui gerar o Pix agora. Digite 2 para pagar com cartão ou boleto.", nil
+	}
+
+	caption := fmt.Sprintf("Pix de %s · válido até %s", params.Product.Name, formatDeadline(charge.ExpiresAt))
+	if err := sm.whatsappClient.SendImage(session.UserID, charge.QRCodeImageURL, caption); err != nil {
+		// O código copia e cola abaixo é suficiente para concluir o pagamento.
+		log.Printf("AVISO: Falha ao enviar o QR Code do Pix para %s: %v", session.UserID, err)
+	}
+	if err := sm.whatsappClient.SendMessage(session.UserID, "Escaneie o QR Code ou use o Pix copia e cola abaixo 👇"); err != nil {
+		log.Printf("AVISO: Falha ao enviar instruções do Pix para %s: %v", session.UserID, err)
+	}
+
+	session.State = StateAwaitingPayment
+	session.Payment = PaymentData{
+		Reference:   charge.PaymentIntentID,
+		Method:      paymentMethodPix,
+		RequestedAt: time.Now(),
+		ExpiresAt:   charge.ExpiresAt,
+	}
+	// O código vai sozinho na mensagem para facilitar a cópia no celular.
+	return charge.CopyPasteCode, nil
+}
+
+// startCheckout gera o link de pagamento do produto da sessão e passa a aguardar o pagamento.
+func (sm *StateManager) startCheckout(ctx context.Context, session *UserSession) (string, error) {
+	params, ok, err := sm.checkoutParams(ctx, session)
+	if err != nil || !ok {
+		return paymentErrorMessage, err
+	}
+
+	// Gera o link de checkout da Stripe
+	link, err := sm.stripeClient.CreateCheckoutSession(params)
+	if err != nil {
+		log.Printf("ERRO: Falha ao criar a sessão de checkout da Stripe: %v", err)
+		return paymentErrorMessage, nil
+	}
+
+	session.State = StateAwaitingPayment // Muda o estado para aguardar a confirmação do pagamento
+	session.Payment = PaymentData{
+		Reference:   link.ID,
+		Method:      paymentMethodCheckout,
+		URL:         link.URL,
+		RequestedAt: time.Now(),
+		ExpiresAt:   link.ExpiresAt,
+	}
+	return fmt.Sprintf("Tudo pronto! Para finalizar, efetue o pagamento através deste link seguro: %s\n\n"+
+		"O link é válido até %s.", link.URL, formatDeadline(link.ExpiresAt)), nil
+}
+
+// handlePaymentRetry oferece um novo link de pagamento após um pagamento não concluído.
+func (sm *StateManager) handlePaymentRetry(ctx context.Context, session *UserSession, input string) (string, error) {
+	switch input {
+	case "ok":
+		return sm.choosePaymentMethod(ctx, session)
+	case "menu":
+		session.State = StateInitial
+		return "Tudo bem! Envie qualquer mensagem para ver o menu principal.", nil
+	default:
+		return "Digite OK para gerar um novo link de pagamento ou MENU para voltar ao menu principal.", nil
+	}
+}
+
+// handleAwaitingPayment responde ao cliente enquanto a cobrança está pendente. A
+// sessão continua aguardando o pagamento, acompanhada pelos lembretes, até que o
+// cliente volte ao menu; a cobrança em si continua válida.
+func (sm *StateManager) handleAwaitingPayment(session *UserSession, input string) string {
+	if input == "menu" {
+		session.State = StateAwaitingOption
+		session.Payment = PaymentData{}
+		return "Tudo bem! Se você concluir o pagamento mesmo assim, avisaremos por aqui.\n\n" + mainMenu
+	}
+	return pendingPaymentMessage(session.Payment) + "\n\nDigite MENU para voltar ao menu principal."
+}
+
+// completePayment tira a sessão da espera de pagamento após a confirmação. A
+// conversa volta ao início: a próxima mensagem do cliente abre o menu principal.
+func (sm *StateManager) completePayment(ctx context.Context, userID string) {
+	dbSession, err := sm.dbStore.LoadSession(ctx, userID)
+	if err != nil {
+		log.Printf("AVISO: Falha ao carregar a sessão do usuário %s após o pagamento: %v", userID, err)
+		return
+	}
+	session := copyFromDBSession(dbSession)
+	if session.State != StateAwaitingPayment && session.State != StatePaymentRetry && session.State != StateChoosePaymentMethod {
+		return
+	}
+
+	session.State = StateInitial
+	session.Payment = PaymentData{}
+	if err := sm.dbStore.SaveSession(ctx, copyToDBSession(session)); err != nil {
+		log.Printf("AVISO: Falha ao atualizar a sessão do usuário %s após o pagamento: %v", userID, err)
+	}
+}
+
+// PaymentPending envia ao cliente o boleto gerado no checkout. A conta só é
+// provisionada quando a Stripe confirmar a compensação.
+// É chamada pelo webhook da Stripe.
+func (sm *StateManager) PaymentPending(userID string, boleto *stripe.BoletoDetails) error {
+	// O boleto tem prazo próprio de compensação: a sessão deixa de receber lembretes do link.
+	_, err := sm.dbStore.UpdateSession(context.Background(), userID,
+		map[string]interface{}{"state": string(StateAwaitingPayment)},
+		map[string]interface{}{"payment.method": paymentMethodBoleto})
+	if err != nil {
+		log.Printf("AVISO: Falha ao registrar o boleto na sessão do usuário %s: %v", userID, err)
+	}
+
+	if boleto == nil {
+		return sm.whatsappClient.SendMessage(userID, "Recebemos seu pedido! 🧾\n\n"+
+			"Assim que o pagamento for compensado, avisaremos você por aqui.")
+	}
+
+	msg := fmt.Sprintf("Recebemos seu pedido! 🧾\n\n"+
+		"Seu boleto vence em %s. Use a linha digitável abaixo para pagar:\n\n%s\n\n"+
+		"PDF do boleto: %s\n\n"+
+		"A compensação pode levar até 3 dias úteis. Avisaremos você por aqui assim que o pagamento for confirmado.",
+		boleto.ExpiresAt.Format("02/01/2006"), boleto.Number, boleto.PDFURL)
+	return sm.whatsappClient.SendMessage(userID, msg)
+}
+
+// PaymentFailed avisa o cliente que o pagamento assíncrono não foi concluído
+// (ex.: boleto vencido ou Pix expirado). Se a conversa ainda aguardava esse
+// pagamento, oferece um novo link; caso contrário, apenas informa o cliente.
+// É chamada pelo webhook da Stripe.
+func (sm *StateManager) PaymentFailed(userID string) error {
+	// A partir daqui conta o prazo para o cliente pedir um novo link antes de voltar ao menu.
+	retry, err := sm.dbStore.UpdateSession(context.Background(), userID,
+		map[string]interface{}{"state": string(StateAwaitingPayment)},
+		map[string]interface{}{"state": string(StatePaymentRetry), "payment": database.PaymentData{RequestedAt: time.Now()}})
+	if err != nil {
+		return fmt.Errorf("falha ao salvar sessão no StateManager: %w", err)
+	}
+
+	msg := "Não identificamos o seu pagamento dentro do prazo, por isso o pedido não foi concluído."
+	if retry {
+		msg += "\n\nDigite OK para gerar um novo link de pagamento ou MENU para voltar ao menu principal."
+	} else {
+		msg += "\n\nQuando quiser retomar, é só enviar qualquer mensagem para ver o menu principal."
+	}
+	return sm.whatsappClient.SendMessage(userID, msg)
+}
+
+// CheckoutExpired trata o link de checkout que expirou sem pagamento. Links antigos,
+// substituídos por um novo pedido do cliente, são ignorados.
+// É chamada pelo webhook da Stripe.
+func (sm *StateManager) CheckoutExpired(userID, checkoutSessionID string) error {
+	dbSession, err := sm.dbStore.LoadSession(context.Background(), userID)
+	if err != nil {
+		return fmt.Errorf("falha ao carregar sessão no StateManager: %w", err)
+	}
+	if State(dbSession.State) != StateAwaitingPayment || dbSession.Payment.Reference != checkoutSessionID {
+		return nil
+	}
+	return sm.PaymentFailed(userID)
+}
+
+// formatDeadline formata um prazo de pagamento no fuso de São Paulo.
+func formatDeadline(t time.Time) string {
+	return t.In(saoPaulo).Format("15:04 de 02/01")
+}
@@ -0,0 +1,389 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/credentials"
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/whm"
+)
+
+const (
+	// maxProvisioningAttempts é o número de tentativas de cada etapa antes de a
+	// equipe ser acionada.
+	maxProvisioningAttempts = 8
+	// maxProvisioningBackoff limita o intervalo entre as tentativas.
+	maxProvisioningBackoff = time.Hour
+	// provisioningBatchSize é o número máximo de jobs executados por rodada.
+	provisioningBatchSize = 20
+	// provisioningLease é o prazo de uma execução do job. Passado esse prazo (ex.:
+	// o processo caiu no meio), o job pode ser retomado por outra execução.
+	provisioningLease = 15 * time.Minute
+)
+
+// Etapas do provisionamento, executadas nesta ordem.
+const (
+	stepCreateAccount   = "create_account"
+	stepConfigureDNS    = "configure_dns"
+	stepIssueSSL        = "issue_ssl"
+	stepSendCredentials = "send_credentials"
+	stepNotify          = "notify"
+	stepRegisterDomain  = "register_domain"
+	stepTransferDomain  = "transfer_domain"
+)
+
+var provisioningSteps = []string{stepCreateAccount, stepConfigureDNS, stepIssueSSL, stepSendCredentials, stepNotify, stepRegisterDomain, stepTransferDomain}
+
+// criticalSteps são as etapas sem as quais não há conta a entregar. As demais,
+// se esgotarem as tentativas, são repassadas à equipe e o job segue adiante.
+var criticalSteps = map[string]bool{stepCreateAccount: true}
+
+// newProvisioningJob monta um job com todas as etapas pendentes.
+func newProvisioningJob(userID, domain, contactEmail, productID string) *database.ProvisioningJob {
+	job := &database.ProvisioningJob{
+		UserID:       userID,
+		Domain:       domain,
+		ContactEmail: contactEmail,
+		ProductID:    productID,
+	}
+	for _, name := range provisioningSteps {
+		job.Steps = append(job.Steps, database.ProvisioningStep{Name: name, Status: database.JobStatusPending})
+	}
+	return job
+}
+
+// EnqueueProvisioning grava um job para refazer um provisionamento que falhou.
+// É chamada pelo webhook da Stripe, que confirma o evento em seguida.
+func (sm *StateManager) EnqueueProvisioning(userID, domain, contactEmail, productID string, cause error) error {
+	job := newProvisioningJob(userID, domain, contactEmail, productID)
+	sm.attachTransfer(context.Background(), job)
+	job.LastError = cause.Error()
+	job.NextAttemptAt = time.Now().Add(provisioningBackoff(1))
+	if err := sm.dbStore.EnqueueProvisioningJob(context.Background(), job); err != nil {
+		return err
+	}
+	sm.clearSessionTransfer(context.Background(), userID, domain)
+
+	sm.notify(userID, "Seu pagamento foi confirmado! ✅\n\n"+
+		"Estamos finalizando a criação da sua conta e avisaremos você por aqui assim que estiver pronta.")
+	return nil
+}
+
+// RunProvisioningJobs executa os jobs de provisionamento pendentes a cada intervalo
+// e sempre que um job novo é gravado, até que o contexto seja cancelado.
+func (sm *StateManager) RunProvisioningJobs(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sm.retryProvisioningJobs(ctx)
+		case <-sm.provisioningWake:
+			sm.retryProvisioningJobs(ctx)
+		}
+	}
+}
+
+// wakeProvisioning avisa RunProvisioningJobs de um job novo. Se já houver um aviso
+// pendente, o job entra na mesma rodada.
+func (sm *StateManager) wakeProvisioning() {
+	select {
+	case sm.provisioningWake <- struct{}{}:
+	default:
+	}
+}
+
+// retryProvisioningJobs executa uma rodada dos jobs cuja próxima tentativa já venceu.
+// Cada job é assumido atomicamente por lease, então um job nunca roda duas vezes ao
+// mesmo tempo, mesmo com mais de uma instância do serviço.
+func (sm *StateManager) retryProvisioningJobs(ctx context.Context) {
+	for i := 0; i < provisioningBatchSize; i++ {
+		job, err := sm.dbStore.ClaimDueProvisioningJob(ctx, time.Now(), provisioningLease)
+		if err != nil {
+			log.Printf("ERRO: %v", err)
+			return
+		}
+		if job == nil {
+			return
+		}
+		sm.runProvisioningJob(ctx, job)
+	}
+}
+
+// runProvisioningJob executa as etapas pendentes de um job assumido e grava o
+// resultado. Uma falha interrompe a execução até a próxima tentativa; uma falha
+// definitiva em etapa essencial desfaz o que foi criado e aciona a equipe.
+func (sm *StateManager) runProvisioningJob(ctx context.Context, job *database.ProvisioningJob) {
+	job.Attempts++
+	defer func() {
+		if err := sm.dbStore.SaveProvisioningJob(ctx, job); err != nil {
+			log.Printf("ERRO: Job de provisionamento do usuário %s: %v", job.UserID, err)
+		}
+	}()
+
+	for i := range job.Steps {
+		step := &job.Steps[i]
+		if step.Status == database.JobStatusDone || step.Status == database.StepStatusSkipped {
+			continue
+		}
+
+		step.Attempts++
+		err := sm.runProvisioningStep(ctx, job, step.Name)
+		if err == nil {
+			step.Status = database.JobStatusDone
+			step.LastError = ""
+			step.CompletedAt = time.Now()
+			continue
+		}
+
+		if errors.Is(err, database.ErrLeaseLost) {
+			// Outra execução assumiu o job e segue a partir daqui.
+			return
+		}
+		step.LastError = err.Error()
+		job.LastError = fmt.Sprintf("%s: %v", step.Name, err)
+		log.Printf("AVISO: Etapa %s do provisionamento do usuário %s falhou (tentativa %d): %v", step.Name, job.UserID, step.Attempts, err)
+
+		if step.Attempts < maxProvisioningAttempts {
+			job.Status = database.JobStatusPending
+			job.NextAttemptAt = time.Now().Add(provisioningBackoff(step.Attempts))
+			return
+		}
+		if criticalSteps[step.Name] {
+			step.Status = database.JobStatusFailed
+			sm.compensateProvisioning(ctx, job, err)
+			return
+		}
+
+		step.Status = database.StepStatusSkipped
+		sm.alertOperators(ctx, "provisioning_step", job.UserID, fmt.Sprintf(
+			"Etapa %s da conta %s (%s) esgotou as tentativas e precisa ser concluída manualmente: %v",
+			step.Name, job.Username, job.Domain, err))
+		if step.Name == stepSendCredentials {
+			sm.notify(job.UserID, "Por segurança, nossa equipe vai enviar seus dados de acesso em instantes por aqui.")
+		}
+	}
+
+	job.Status = database.JobStatusDone
+	job.LastError = ""
+	log.Printf("SUCESSO: Conta para o domínio %s (usuário %s) provisionada com sucesso!", job.Domain, job.UserID)
+}
+
+// runProvisioningStep executa uma etapa do provisionamento. Todas as etapas
+// podem ser repetidas sem efeitos duplicados.
+func (sm *StateManager) runProvisioningStep(ctx context.Context, job *database.ProvisioningJob, name string) error {
+	switch name {
+	case stepCreateAccount:
+		return sm.createAccountStep(ctx, job)
+	case stepConfigureDNS:
+		return sm.configureDNSStep(job)
+	case stepIssueSSL:
+		return sm.whmClient.StartAutoSSL(job.Username)
+	case stepSendCredentials:
+		return sm.sendCredentialsStep(ctx, job)
+	case stepNotify:
+		msg := fmt.Sprintf("Ótima notícia! ✅\n\nSeu pagamento foi confirmado e sua conta para o domínio `%s` foi criada com sucesso!", job.Domain)
+		return sm.whatsappClient.SendMessage(job.UserID, msg)
+	case stepRegisterDomain:
+		return sm.registerDomainStep(ctx, job)
+	case stepTransferDomain:
+		return sm.transferDomainStep(ctx, job)
+	}
+	return fmt.Errorf("etapa de provisionamento desconhecida: %s", name)
+}
+
+// createAccountStep cria a conta no WHM e a vincula ao cadastro do cliente.
+func (sm *StateManager) createAccountStep(ctx context.Context, job *database.ProvisioningJob) error {
+	if job.Plan == "" {
+		plan, err := sm.provisioningPlan(job.ProductID)
+		if err != nil {
+			return err
+		}
+		job.Plan = plan
+	}
+
+	if job.Username != "" {
+		owned, err := sm.jobAccountExists(job)
+		if err != nil {
+			return err
+		}
+		if owned {
+			return sm.linkAccount(ctx, job)
+		}
+	}
+
+	if job.Username == "" {
+		username, err := sm.whmClient.GenerateUsername(job.Domain)
+		if err != nil {
+			return fmt.Errorf("falha ao gerar o nome de usuário da conta: %w", err)
+		}
+		// Grava o nome antes de criar a conta: se a resposta do WHM se perder, a
+		// próxima tentativa encontra a conta em vez de criar outra.
+		job.Username = username
+		if err := sm.dbStore.SaveProvisioningJob(ctx, job); err != nil {
+			return err
+		}
+	}
+
+	// A senha inicial é descartada; o cliente recebe uma nova na etapa de credenciais.
+	password, err := credentials.GeneratePassword()
+	if err != nil {
+		return fmt.Errorf("falha ao gerar a senha da conta: %w", err)
+	}
+	_, err = sm.whmClient.CreateAccount(whm.CreateAccountParams{
+		Username:     job.Username,
+		Domain:       job.Domain,
+		Plan:         job.Plan,
+		Password:     password,
+		ContactEmail: job.ContactEmail,
+	})
+	if err != nil {
+		return fmt.Errorf("falha ao criar conta no WHM: %w", err)
+	}
+	return sm.linkAccount(ctx, job)
+}
+
+// jobAccountExists indica se a conta job.Username existe no WHM para o domínio do
+// job. O nome é gravado antes da criação; se ela falhou, outro job pode ter criado
+// uma conta com o mesmo nome (ex.: meusite.com.br e meusite.net). Nesse caso o nome
+// é descartado, para que o job gere outro e nunca use a conta de outro cliente.
+func (sm *StateManager) jobAccountExists(job *database.ProvisioningJob) (bool, error) {
+	domain, err := sm.whmClient.AccountDomain(job.Username)
+	if err != nil {
+		return false, err
+	}
+	if domain == "" {
+		return false, nil
+	}
+	if !strings.EqualFold(domain, job.Domain) {
+		log.Printf("AVISO: A conta %s pertence ao domínio %s, não a %s; o job do usuário %s vai gerar outro nome.",
+			job.Username, domain, job.Domain, job.UserID)
+		job.Username = ""
+		return false, nil
+	}
+	return true, nil
+}
+
+// provisioningPlan retorna o pacote do WHM do produto comprado.
+func (sm *StateManager) provisioningPlan(productID string) (string, error) {
+	if productID == "" {
+		return defaultWHMPackage, nil
+	}
+	product, ok := sm.catalog.FindByID(productID)
+	if !ok || !product.IsHostingPlan() {
+		return "", fmt.Errorf("produto %s não é um plano de hospedagem do catálogo", productID)
+	}
+	return product.WHMPackage, nil
+}
+
+// linkAccount vincula a conta ao cadastro do cliente para a Área do Cliente (DNS, e-mails etc.)
+func (sm *StateManager) linkAccount(ctx context.Context, job *database.ProvisioningJob) error {
+	customer, err := sm.dbStore.LoadCustomer(ctx, job.UserID)
+	if err != nil {
+		return err
+	}
+	if hasActiveHosting(customer) && customer.WHMUsername != job.Username {
+		// O cadastro guarda uma única hospedagem; a equipe vincula a segunda manualmente.
+		sm.alertOperators(ctx, "second_account", job.UserID, fmt.Sprintf(
+			"Conta %s (%s) criada para um cliente que já tem a conta %s (%s); o cadastro não foi alterado",
+			job.Username, job.Domain, customer.WHMUsername, customer.Domain))
+		return nil
+	}
+	return sm.dbStore.UpdateCustomer(ctx, job.UserID, map[string]interface{}{
+		"domain":         job.Domain,
+		"whm_username":   job.Username,
+		"whm_package":    job.Plan,
+		"account_status": database.AccountStatusActive,
+	})
+}
+
+// configureDNSStep garante que o "www" do domínio aponte para a conta.
+func (sm *StateManager) configureDNSStep(job *database.ProvisioningJob) error {
+	records, err := sm.whmClient.DumpZone(job.Domain)
+	if err != nil {
+		return err
+	}
+	wwwName := whm.RecordFQDN("www", job.Domain)
+	for _, record := range records {
+		if record.Name == wwwName {
+			return nil
+		}
+	}
+	return sm.whmClient.AddZoneRecord(job.Domain, whm.ZoneRecordParams{
+		Name:  "www",
+		Type:  whm.RecordTypeCNAME,
+		Value: job.Domain + ".",
+	})
+}
+
+// sendCredentialsStep define uma nova senha para a conta e a envia ao cliente por
+// um link de uso único. A senha nunca é registrada em log nem gravada em texto puro;
+// se a etapa for repetida, a senha é redefinida de novo e os links anteriores, que
+// trazem a senha antiga, são revogados ao emitir o novo.
+func (sm *StateManager) sendCredentialsStep(ctx context.Context, job *database.ProvisioningJob) error {
+	password, err := credentials.GeneratePassword()
+	if err != nil {
+		return fmt.Errorf("falha ao gerar a senha da conta: %w", err)
+	}
+	if err := sm.whmClient.ChangePassword(job.Username, password); err != nil {
+		return fmt.Errorf("falha ao definir a senha da conta %s: %w", job.Username, err)
+	}
+
+	link, expiresAt, err := sm.credentials.Issue(ctx, job.UserID, job.Username, job.Domain, password)
+	if err != nil {
+		return fmt.Errorf("falha ao emitir o link de credenciais da conta %s: %w", job.Username, err)
+	}
+	return sm.whatsappClient.SendMessage(job.UserID, fmt.Sprintf("🔐 Seus dados de acesso estão neste link seguro: %s\n\n"+
+		"Ele só pode ser aberto uma vez e vale até %s. Anote a senha em um local seguro.",
+		link, formatDeadline(expiresAt)))
+}
+
+// compensateProvisioning desfaz a criação parcial da conta após uma falha
+// definitiva e repassa o caso à equipe, que decide entre concluir manualmente
+// ou estornar o pagamento.
+func (sm *StateManager) compensateProvisioning(ctx context.Context, job *database.ProvisioningJob, cause error) {
+	job.Status = database.JobStatusFailed
+
+	summary := fmt.Sprintf("Provisionamento do domínio %s falhou %d vezes: %v", job.Domain, maxProvisioningAttempts, cause)
+	if username := job.Username; username != "" {
+		exists, err := sm.jobAccountExists(job)
+		switch {
+		case err != nil:
+			summary += fmt.Sprintf(". Não foi possível verificar a conta %s no WHM: %v", username, err)
+		case exists:
+			if err := sm.whmClient.TerminateAccount(job.Username); err != nil {
+				summary += fmt.Sprintf(". A conta parcial %s NÃO foi removida: %v", job.Username, err)
+			} else {
+				summary += fmt.Sprintf(". A conta parcial %s foi removida", job.Username)
+				job.Status = database.JobStatusCompensated
+			}
+		case job.Username == "":
+			summary += fmt.Sprintf(". A conta %s é de outro domínio e não foi removida", username)
+			job.Status = database.JobStatusCompensated
+		default:
+			job.Status = database.JobStatusCompensated
+		}
+	}
+
+	log.Printf("ERRO CRÍTICO: %s (usuário %s)", summary, job.UserID)
+	sm.alertOperators(ctx, "provisioning_failed", job.UserID, summary)
+	sm.notify(job.UserID, "Tivemos um problema ao criar a sua conta, mas fique tranquilo: seu pagamento está registrado. "+
+		"Nossa equipe já foi acionada e vai falar com você por aqui para concluir a ativação ou estornar o valor.")
+}
+
+// provisioningBackoff retorna o intervalo até a próxima tentativa, dobrando a cada falha.
+func provisioningBackoff(attempts int) time.Duration {
+	backoff := time.Minute << uint(attempts-1)
+	if backoff <= 0 || backoff > maxProvisioningBackoff {
+		return maxProvisioningBackoff
+	}
+	return backoff
+}
@@ -0,0 +1,129 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+)
+
+// ReminderPolicy define quando lembrar o cliente de uma cobrança pendente.
+type ReminderPolicy struct {
+	// Interval é o intervalo entre as verificações das sessões pendentes.
+	Interval time.Duration
+	// Schedule lista, a partir da geração do link, quando cada lembrete é enviado.
+	Schedule []time.Duration
+	// AbandonAfter é o prazo para o cliente pedir um novo link depois que o
+	// anterior expirou; depois dele, a conversa volta ao menu principal.
+	AbandonAfter time.Duration
+}
+
+// RunPaymentReminders verifica periodicamente as sessões aguardando pagamento até
+// que o contexto seja cancelado.
+func (sm *StateManager) RunPaymentReminders(ctx context.Context, policy ReminderPolicy) {
+	ticker := time.NewTicker(policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sm.sendPaymentReminders(ctx, policy)
+		}
+	}
+}
+
+// sendPaymentReminders envia os lembretes vencidos, trata links expirados cujo
+// evento da Stripe não chegou e devolve ao menu os pedidos abandonados.
+func (sm *StateManager) sendPaymentReminders(ctx context.Context, policy ReminderPolicy) {
+	dbSessions, err := sm.dbStore.FindSessionsByState(ctx, string(StateAwaitingPayment), string(StatePaymentRetry))
+	if err != nil {
+		log.Printf("ERRO: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range dbSessions {
+		session := copyFromDBSession(&dbSessions[i])
+		// Sessões anteriores ao registro da cobrança não têm como ser acompanhadas.
+		if session.Payment.RequestedAt.IsZero() {
+			continue
+		}
+
+		if session.State == StatePaymentRetry {
+			if now.Sub(session.Payment.RequestedAt) >= policy.AbandonAfter {
+				sm.abandonPayment(ctx, session)
+			}
+			continue
+		}
+
+		// O boleto segue o próprio prazo de compensação.
+		if session.Payment.Method == paymentMethodBoleto {
+			continue
+		}
+		if !session.Payment.ExpiresAt.IsZero() && now.After(session.Payment.ExpiresAt) {
+			if err := sm.PaymentFailed(session.UserID); err != nil {
+				log.Printf("ERRO: Falha ao tratar a cobrança expirada do usuário %s: %v", session.UserID, err)
+			}
+			continue
+		}
+
+		next := session.Payment.Reminders
+		if next >= len(policy.Schedule) || now.Sub(session.Payment.RequestedAt) < policy.Schedule[next] {
+			continue
+		}
+		sm.sendPaymentReminder(ctx, session)
+	}
+}
+
+// sendPaymentReminder registra o envio do lembrete e lembra o cliente da cobrança
+// pendente. Se a sessão mudou desde a consulta (ex.: o pagamento foi confirmado),
+// nada é enviado.
+func (sm *StateManager) sendPaymentReminder(ctx context.Context, session *UserSession) {
+	updated, err := sm.dbStore.IncrementPaymentReminders(ctx, session.UserID, string(StateAwaitingPayment), session.Payment.RequestedAt)
+	if err != nil {
+		log.Printf("AVISO: Falha ao registrar o lembrete de pagamento do usuário %s: %v", session.UserID, err)
+		return
+	}
+	if !updated {
+		return
+	}
+	sm.notify(session.UserID, pendingPaymentMessage(session.Payment))
+}
+
+// pendingPaymentMessage lembra o cliente da cobrança pendente e de como concluí-la.
+func pendingPaymentMessage(payment PaymentData) string {
+	msg := "Seu pedido ainda está aguardando pagamento. 🙂"
+	switch {
+	case payment.Method == paymentMethodBoleto:
+		msg += "\n\nO boleto já foi enviado. Assim que a compensação for confirmada, avisaremos você por aqui."
+	case payment.URL != "":
+		msg += fmt.Sprintf("\n\nVocê pode concluir por este link seguro: %s\n\nO link é válido até %s.",
+			payment.URL, formatDeadline(payment.ExpiresAt))
+	case !payment.ExpiresAt.IsZero():
+		msg += fmt.Sprintf("\n\nO código enviado é válido até %s.", formatDeadline(payment.ExpiresAt))
+	}
+	return msg
+}
+
+// abandonPayment encerra o pedido sem pagamento e devolve a conversa ao menu principal,
+// se o cliente não tiver respondido desde a consulta.
+func (sm *StateManager) abandonPayment(ctx context.Context, session *UserSession) {
+	updated, err := sm.dbStore.UpdateSession(ctx, session.UserID,
+		map[string]interface{}{"state": string(StatePaymentRetry), "payment.requested_at": session.Payment.RequestedAt},
+		map[string]interface{}{"state": string(StateInitial), "payment": database.PaymentData{}})
+	if err != nil {
+		log.Printf("AVISO: Falha ao encerrar o pedido pendente do usuário %s: %v", session.UserID, err)
+		return
+	}
+	if !updated {
+		return
+	}
+
+	log.Printf("Pedido sem pagamento do usuário %s encerrado; conversa devolvida ao menu.", session.UserID)
+	sm.notify(session.UserID, "Como não recebemos uma resposta, encerramos o pedido pendente. "+
+		"Quando quiser retomar, é só enviar qualquer mensagem para ver o menu principal.")
+}
@@ -0,0 +1,150 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/products"
+	"github.com/dresbach/dresbach-assistente/pkg/stripe"
+)
+
+// Motivos registrados no WHM ao suspender por estorno ou contestação.
+const (
+	refundSuspensionReason  = "Pagamento estornado"
+	disputeSuspensionReason = "Pagamento contestado (chargeback)"
+)
+
+// scheduleDiagnostic registra no cadastro que o Diagnóstico Tech Ops foi pago e
+// aguarda o contato da equipe.
+func (sm *StateManager) scheduleDiagnostic(ctx context.Context, userID string) {
+	err := sm.dbStore.UpdateCustomer(ctx, userID, map[string]interface{}{"diagnostic_status": database.DiagnosticStatusScheduled})
+	if err != nil {
+		log.Printf("AVISO: Falha ao registrar o diagnóstico do usuário %s: %v", userID, err)
+	}
+}
+
+// PaymentReversed desfaz a entrega de um pagamento estornado ou contestado: suspende
+// a hospedagem ou cancela o diagnóstico, registra o evento no cadastro do cliente e
+// avisa o cliente e a equipe. Estornos parciais são apenas registrados.
+// É chamada pelo webhook da Stripe.
+func (sm *StateManager) PaymentReversed(reversal stripe.PaymentReversal) error {
+	ctx := context.Background()
+
+	customer, err := sm.reversalCustomer(ctx, reversal)
+	if err != nil {
+		// Sem cadastro não há o que desfazer; a equipe verifica manualmente.
+		sm.alertOperators(ctx, "payment_reversal", reversal.UserID, fmt.Sprintf(
+			"%s da cobrança %s sem cliente identificado (cliente Stripe %q): %v",
+			reversalLabel(reversal.Kind), reversal.ChargeID, reversal.StripeCustomerID, err))
+		return nil
+	}
+
+	event := database.FinancialEvent{
+		Kind:      reversal.Kind,
+		ChargeID:  reversal.ChargeID,
+		ProductID: reversal.ProductID,
+		Amount:    reversal.Amount,
+		Currency:  reversal.Currency,
+		Reason:    reversal.Reason,
+		CreatedAt: time.Now(),
+	}
+
+	// action vai para o registro e a fila da equipe; outcome, para o cliente.
+	var action, outcome string
+	set := map[string]interface{}{}
+	switch {
+	case !reversal.Full:
+		action = "estorno parcial registrado; nenhuma ação automática"
+	case reversal.ProductID != "" && !sm.isHostingProduct(reversal.ProductID):
+		action = "diagnóstico cancelado"
+		outcome = "Por isso, o seu Diagnóstico Tech Ops foi cancelado."
+		set["diagnostic_status"] = database.DiagnosticStatusCanceled
+	case customer.WHMUsername != "" && customer.AccountStatus == database.AccountStatusActive:
+		reason := refundSuspensionReason
+		if reversal.Kind == stripe.ReversalDispute {
+			reason = disputeSuspensionReason
+		}
+		if err := sm.whmClient.SuspendAccount(customer.WHMUsername, reason); err != nil {
+			return fmt.Errorf("falha ao suspender a conta %s no WHM: %w", customer.WHMUsername, err)
+		}
+		set["account_status"] = database.AccountStatusSuspended
+		action = fmt.Sprintf("conta %s suspensa", customer.WHMUsername)
+		outcome = fmt.Sprintf("Por isso, a hospedagem do domínio %s foi suspensa.", customer.Domain)
+	default:
+		action = "nenhuma conta ativa para suspender"
+	}
+
+	if err := sm.dbStore.AddFinancialEvent(ctx, customer.UserID, event, set); err != nil {
+		return err
+	}
+
+	log.Printf("%s da cobrança %s do usuário %s: %s", reversalLabel(reversal.Kind), reversal.ChargeID, customer.UserID, action)
+	sm.alertOperators(ctx, "payment_reversal", customer.UserID, fmt.Sprintf("%s de %s na cobrança %s (motivo: %s): %s",
+		reversalLabel(reversal.Kind), formatAmount(reversal.Amount, reversal.Currency), reversal.ChargeID, reversal.Reason, action))
+	if reversal.Full {
+		sm.notify(customer.UserID, reversalMessage(reversal, outcome))
+	}
+	return nil
+}
+
+// reversalCustomer identifica o cadastro do cliente do pagamento estornado.
+func (sm *StateManager) reversalCustomer(ctx context.Context, reversal stripe.PaymentReversal) (*database.Customer, error) {
+	switch {
+	case reversal.UserID != "":
+		return sm.dbStore.LoadCustomer(ctx, reversal.UserID)
+	case reversal.SubscriptionID != "":
+		return sm.dbStore.FindCustomerBySubscription(ctx, reversal.SubscriptionID)
+	case reversal.StripeCustomerID != "":
+		return sm.dbStore.FindCustomerByStripeID(ctx, reversal.StripeCustomerID)
+	}
+	return nil, fmt.Errorf("pagamento sem usuário, assinatura ou cliente Stripe")
+}
+
+// isHostingProduct indica se o produto é um plano de hospedagem. Produtos fora do
+// catálogo são tratados como hospedagem, o caso em que há algo a suspender.
+func (sm *StateManager) isHostingProduct(productID string) bool {
+	if productID == products.DiagnosticProductID {
+		return false
+	}
+	product, ok := sm.catalog.FindByID(productID)
+	return !ok || product.IsHostingPlan()
+}
+
+// alertOperators adiciona um item à fila de atendimento da equipe.
+func (sm *StateManager) alertOperators(ctx context.Context, kind, userID, summary string) {
+	sm.alertOperatorsWithLink(ctx, kind, userID, summary, "")
+}
+
+// alertOperatorsWithLink adiciona à fila um item com o link de uso único de um dado
+// sigiloso. O link fica apenas no item, fora do resumo e do log.
+func (sm *StateManager) alertOperatorsWithLink(ctx context.Context, kind, userID, summary, link string) {
+	log.Printf("FILA DE ATENDIMENTO [%s] usuário %s: %s", kind, userID, summary)
+	task := &database.OperatorTask{Kind: kind, UserID: userID, Summary: summary, Link: link}
+	if err := sm.dbStore.EnqueueOperatorTask(ctx, task); err != nil {
+		log.Printf("ERRO: %v", err)
+	}
+}
+
+// reversalLabel descreve o tipo de estorno nos registros.
+func reversalLabel(kind string) string {
+	if kind == stripe.ReversalDispute {
+		return "Contestação"
+	}
+	return "Estorno"
+}
+
+// reversalMessage monta o aviso ao cliente sobre o estorno ou a contestação.
+func reversalMessage(reversal stripe.PaymentReversal, outcome string) string {
+	amount := formatAmount(reversal.Amount, reversal.Currency)
+	msg := fmt.Sprintf("O pagamento de %s foi estornado.", amount)
+	if reversal.Kind == stripe.ReversalDispute {
+		msg = fmt.Sprintf("Recebemos uma contestação do pagamento de %s junto à operadora do cartão.", amount)
+	}
+	if outcome != "" {
+		msg += "\n\n" + outcome
+	}
+	return msg + "\n\nSe isso não foi solicitado por você, responda esta mensagem que nossa equipe vai ajudar."
+}
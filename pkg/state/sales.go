@@ -0,0 +1,162 @@
+package state
+
+import (
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/dresbach/dresbach-assistente/pkg/products"
+	"github.com/dresbach/dresbach-assistente/pkg/whatsapp"
+)
+
+// compareOption é o ID da opção "Comparar planos" na lista de planos.
+const compareOption = "comparar"
+
+var siteLimitPattern = regexp.MustCompile(`(?i)até (\d+) sites?`)
+
+// startPlanSelection apresenta os planos de hospedagem e aguarda a escolha.
+func (sm *StateManager) startPlanSelection(session *UserSession) string {
+	plans := sm.catalog.HostingPlans()
+	if len(plans) == 0 {
+		log.Printf("ERRO: Nenhum plano de hospedagem disponível no catálogo")
+		return "Desculpe, nossos planos não estão disponíveis no momento. Por favor, tente novamente mais tarde."
+	}
+
+	session.State = StateSalesPlans
+
+	rows := make([]whatsapp.ListRow, 0, len(plans)+1)
+	for _, plan := range plans {
+		rows = append(rows, whatsapp.ListRow{
+			ID:          plan.ID,
+			Title:       truncate(plan.Name, 24),
+			Description: truncate(planPriceLabel(plan)+" · "+plan.Description, 72),
+		})
+	}
+	rows = append(rows, whatsapp.ListRow{ID: compareOption, Title: "Comparar planos", Description: "Veja os planos lado a lado"})
+
+	body := "Conheça nossos planos de hospedagem 🚀\n\nEscolha um plano para ver os detalhes ou compare todos lado a lado."
+	err := sm.whatsappClient.SendListMessage(session.UserID, body, "Ver planos", []whatsapp.ListSection{
+		{Title: "Planos", Rows: rows},
+	})
+	if err != nil {
+		// Se a mensagem interativa falhar, envia a lista em texto.
+		log.Printf("AVISO: Falha ao enviar lista de planos para %s: %v", session.UserID, err)
+		return planTextList(plans)
+	}
+	return ""
+}
+
+// handleSalesPlans trata a escolha na lista de planos.
+func (sm *StateManager) handleSalesPlans(session *UserSession, input string) string {
+	if input == "menu" {
+		return leaveSales(session)
+	}
+	plans := sm.catalog.HostingPlans()
+
+	if input == compareOption {
+		return comparePlans(plans) + "\n\nDigite o número do plano que deseja contratar."
+	}
+
+	plan, ok := findPlan(plans, input)
+	if !ok {
+		return "Não encontrei esse plano. Escolha um plano da lista, digite o número do plano, \"comparar\" ou MENU para voltar."
+	}
+
+	session.ProductID = plan.ID
+	session.State = StateSalesPlanConfirm
+	return fmt.Sprintf("*%s*\n%s\n\n%s\n\nDeseja contratar este plano?\n1 - Sim, contratar\n2 - Comparar planos\n0 - Ver outros planos",
+		plan.Name, planPriceLabel(plan), plan.Description)
+}
+
+// handleSalesPlanConfirm confirma o plano escolhido e segue para a escolha do domínio.
+func (sm *StateManager) handleSalesPlanConfirm(session *UserSession, input string) string {
+	switch input {
+	case "1", "sim":
+		plan, ok := sm.catalog.FindByID(session.ProductID)
+		if !ok || !plan.IsHostingPlan() {
+			session.ProductID = ""
+			return sm.startPlanSelection(session)
+		}
+		session.State = StateTechOpsAskDomain
+		return fmt.Sprintf("Ótima escolha! O plano %s será ativado no seu domínio.\n\nQual domínio você deseja usar? (ex.: meusite.com.br)\n\nDigite MENU para voltar.", plan.Name)
+	case "2", compareOption:
+		session.State = StateSalesPlans
+		return comparePlans(sm.catalog.HostingPlans()) + "\n\nDigite o número do plano que deseja contratar."
+	case "0":
+		session.ProductID = ""
+		return sm.startPlanSelection(session)
+	case "menu":
+		return leaveSales(session)
+	default:
+		return "Por favor, digite 1 para contratar, 2 para comparar os planos ou 0 para ver outros planos."
+	}
+}
+
+// leaveSales abandona a escolha de plano e volta ao menu principal.
+func leaveSales(session *UserSession) string {
+	session.ProductID = ""
+	session.State = StateAwaitingOption
+	return mainMenu
+}
+
+// findPlan localiza o plano pelo ID (resposta da lista) ou pelo número exibido.
+func findPlan(plans []products.Product, input string) (products.Product, bool) {
+	for i, plan := range plans {
+		if strings.EqualFold(plan.ID, input) || input == fmt.Sprint(i+1) {
+			return plan, true
+		}
+	}
+	return products.Product{}, false
+}
+
+// comparePlans monta o comparativo dos planos com os mesmos campos para cada um.
+func comparePlans(plans []products.Product) string {
+	var b strings.Builder
+	b.WriteString("📊 Comparativo de planos\n")
+	for i, plan := range plans {
+		sites := "-"
+		if m := siteLimitPattern.FindStringSubmatch(plan.Description); m != nil {
+			sites = "até " + m[1]
+		}
+		fmt.Fprintf(&b, "\n%d - *%s*\n", i+1, plan.Name)
+		fmt.Fprintf(&b, "   Preço: %s\n", planPriceLabel(plan))
+		fmt.Fprintf(&b, "   Sites: %s\n", sites)
+		fmt.Fprintf(&b, "   %s\n", plan.Description)
+	}
+	return b.String()
+}
+
+func planTextList(plans []products.Product) string {
+	var b strings.Builder
+	b.WriteString("Conheça nossos planos de hospedagem 🚀\n\n")
+	for i, plan := range plans {
+		fmt.Fprintf(&b, "%d - %s (%s)\n", i+1, plan.Name, planPriceLabel(plan))
+	}
+	b.WriteString("\nDigite o número do plano para ver os detalhes ou \"comparar\" para ver todos lado a lado.")
+	return b.String()
+}
+
+// planPriceLabel formata o preço padrão do plano (ex.: "R$ 29,90/mês").
+func planPriceLabel(plan products.Product) string {
+	price, ok := plan.DefaultPrice()
+	if !ok {
+		return "Consulte o valor"
+	}
+	label := formatAmount(price.Amount, price.Currency)
+	switch price.Interval {
+	case "month":
+		label += "/mês"
+	case "year":
+		label += "/ano"
+	}
+	return label
+}
+
+func truncate(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max-1]) + "…"
+}
@@ -0,0 +1,98 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/whm"
+)
+
+// sslRenewalWindow é a antecedência com que um certificado é exibido como próximo do vencimento.
+const sslRenewalWindow = 14 * 24 * time.Hour
+
+const sslMenuOptions = "1 - Solicitar emissão/renovação agora\n0 - Voltar à Área do Cliente"
+
+// handleClientSSL mostra a situação dos certificados SSL da hospedagem do cliente e
+// permite solicitar uma nova verificação do AutoSSL.
+func (sm *StateManager) handleClientSSL(ctx context.Context, session *UserSession, input string) string {
+	if input == "0" {
+		session.State = StateClientArea
+		return clientAreaMenu
+	}
+
+	customer, err := sm.dbStore.LoadCustomer(ctx, session.UserID)
+	if err != nil {
+		log.Printf("ERRO: Falha ao carregar cliente %s: %v", session.UserID, err)
+		return "Desculpe, não consegui acessar seus dados agora. Por favor, tente novamente mais tarde."
+	}
+	if customer.WHMUsername == "" {
+		session.State = StateClientArea
+		return "Não encontramos uma hospedagem vinculada ao seu cadastro.\n\n" + clientAreaMenu
+	}
+
+	if session.State == StateClientSSL {
+		if input != "1" {
+			return "Por favor, digite 1 para solicitar a emissão do certificado ou 0 para voltar."
+		}
+		if err := sm.whmClient.StartAutoSSL(customer.WHMUsername); err != nil {
+			log.Printf("ERRO: Falha ao iniciar o AutoSSL da conta %s: %v", customer.WHMUsername, err)
+			return "Desculpe, não consegui solicitar o certificado agora. Por favor, tente novamente mais tarde."
+		}
+		session.State = StateClientArea
+		return "Solicitação enviada! 🔒 A emissão costuma levar alguns minutos; consulte esta opção novamente em seguida.\n\n" + clientAreaMenu
+	}
+
+	certs, err := sm.whmClient.AccountCertificates(customer.WHMUsername)
+	if err != nil {
+		log.Printf("ERRO: Falha ao consultar os certificados da conta %s: %v", customer.WHMUsername, err)
+		return "Desculpe, não consegui consultar seus certificados agora. Por favor, tente novamente mais tarde."
+	}
+	problems, err := sm.whmClient.AutoSSLProblems(customer.WHMUsername)
+	if err != nil {
+		// Os certificados já dão a situação principal; os problemas só complementam.
+		log.Printf("AVISO: Falha ao consultar os problemas do AutoSSL da conta %s: %v", customer.WHMUsername, err)
+	}
+
+	session.State = StateClientSSL
+	return formatCertificates(certs, problems, time.Now()) + "\n\n" + sslMenuOptions
+}
+
+// formatCertificates descreve a validade de cada certificado e os problemas do AutoSSL.
+func formatCertificates(certs []whm.SSLCertificate, problems []whm.AutoSSLProblem, now time.Time) string {
+	var b strings.Builder
+	b.WriteString("Certificados SSL 🔒\n")
+	if len(certs) == 0 {
+		b.WriteString("\nNenhum certificado instalado ainda.")
+	}
+	for _, cert := range certs {
+		expiry := cert.NotAfter.In(saoPaulo).Format("02/01/2006")
+		switch {
+		case cert.SelfSigned:
+			fmt.Fprintf(&b, "\n⚠️ %s: certificado autoassinado (os navegadores exibem alerta)", cert.ServerName)
+		case cert.NotAfter.IsZero():
+			// O WHM não informou a validade: sem data, não há como indicar vencimento ou renovação.
+			fmt.Fprintf(&b, "\n🔒 %s: certificado instalado (validade não informada)", cert.ServerName)
+		case cert.Expired(now):
+			fmt.Fprintf(&b, "\n❌ %s: vencido em %s", cert.ServerName, expiry)
+		case cert.NotAfter.Sub(now) < sslRenewalWindow:
+			fmt.Fprintf(&b, "\n⏳ %s: válido até %s (renovação automática em andamento)", cert.ServerName, expiry)
+		default:
+			fmt.Fprintf(&b, "\n✅ %s: válido até %s", cert.ServerName, expiry)
+		}
+		if cert.Issuer != "" && !cert.SelfSigned {
+			fmt.Fprintf(&b, " · %s", cert.Issuer)
+		}
+	}
+
+	if len(problems) > 0 {
+		b.WriteString("\n\nDomínios que o AutoSSL não conseguiu proteger:")
+		for _, p := range problems {
+			fmt.Fprintf(&b, "\n• %s: %s", p.Domain, p.Problem)
+		}
+		b.WriteString("\n\nNa maioria dos casos, o domínio ainda não aponta para a nossa hospedagem.")
+	}
+	return b.String()
+}
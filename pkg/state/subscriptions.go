@@ -0,0 +1,166 @@
+package state
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/stripe"
+)
+
+// suspensionReason é o motivo registrado no WHM ao suspender por falta de pagamento.
+const suspensionReason = "Falta de pagamento da assinatura"
+
+// LinkSubscription vincula a assinatura da Stripe ao cadastro do usuário.
+// O cadastro guarda uma única hospedagem: uma segunda assinatura não substitui a
+// primeira, cujos eventos deixariam de encontrar o cliente, e é repassada à equipe.
+// É chamada pelo webhook da Stripe após a conclusão do checkout.
+func (sm *StateManager) LinkSubscription(userID, subscriptionID string) error {
+	ctx := context.Background()
+
+	customer, err := sm.dbStore.LoadCustomer(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if hasActiveHosting(customer) && customer.SubscriptionID != "" && customer.SubscriptionID != subscriptionID {
+		sm.alertOperators(ctx, "second_subscription", userID, fmt.Sprintf(
+			"Cliente com a hospedagem %s (assinatura %s) contratou a assinatura %s, que não foi vinculada ao cadastro",
+			customer.Domain, customer.SubscriptionID, subscriptionID))
+		return nil
+	}
+	return sm.dbStore.UpdateCustomer(ctx, userID, map[string]interface{}{"subscription_id": subscriptionID})
+}
+
+// hasActiveHosting indica se o cliente já tem uma conta de hospedagem não encerrada.
+func hasActiveHosting(customer *database.Customer) bool {
+	return customer.WHMUsername != "" && customer.AccountStatus != database.AccountStatusTerminated
+}
+
+// subscriptionCustomer carrega o cliente vinculado à assinatura. Assinaturas sem
+// cliente retornam stripe.ErrSubscriptionNotFound.
+func (sm *StateManager) subscriptionCustomer(ctx context.Context, subscriptionID string) (*database.Customer, error) {
+	customer, err := sm.dbStore.FindCustomerBySubscription(ctx, subscriptionID)
+	if errors.Is(err, database.ErrCustomerNotFound) {
+		return nil, fmt.Errorf("%w: %s", stripe.ErrSubscriptionNotFound, subscriptionID)
+	}
+	return customer, err
+}
+
+// SubscriptionPaid reativa a conta suspensa quando uma renovação é paga.
+func (sm *StateManager) SubscriptionPaid(subscriptionID string) error {
+	ctx := context.Background()
+
+	customer, err := sm.subscriptionCustomer(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if customer.AccountStatus != database.AccountStatusSuspended {
+		return nil
+	}
+
+	if err := sm.whmClient.UnsuspendAccount(customer.WHMUsername); err != nil {
+		return fmt.Errorf("falha ao reativar a conta %s no WHM: %w", customer.WHMUsername, err)
+	}
+	if err := sm.dbStore.UpdateCustomer(ctx, customer.UserID, map[string]interface{}{"account_status": database.AccountStatusActive}); err != nil {
+		return err
+	}
+
+	log.Printf("SUCESSO: Conta %s reativada após pagamento da assinatura %s", customer.WHMUsername, subscriptionID)
+	sm.notify(customer.UserID, fmt.Sprintf("Pagamento confirmado! ✅\n\nSua hospedagem do domínio %s foi reativada.", customer.Domain))
+	return nil
+}
+
+// SubscriptionPaymentFailed avisa o cliente sobre a falha de cobrança e suspende
+// a conta quando a Stripe esgota as retentativas.
+func (sm *StateManager) SubscriptionPaymentFailed(subscriptionID string, finalAttempt bool) error {
+	ctx := context.Background()
+
+	customer, err := sm.subscriptionCustomer(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if !finalAttempt {
+		sm.notify(customer.UserID, fmt.Sprintf("Não conseguimos processar o pagamento da sua hospedagem do domínio %s. "+
+			"Vamos tentar novamente nos próximos dias; verifique se sua forma de pagamento está em dia para evitar a suspensão.", customer.Domain))
+		return nil
+	}
+	if customer.AccountStatus == database.AccountStatusSuspended {
+		return nil
+	}
+
+	if err := sm.whmClient.SuspendAccount(customer.WHMUsername, suspensionReason); err != nil {
+		return fmt.Errorf("falha ao suspender a conta %s no WHM: %w", customer.WHMUsername, err)
+	}
+	if err := sm.dbStore.UpdateCustomer(ctx, customer.UserID, map[string]interface{}{"account_status": database.AccountStatusSuspended}); err != nil {
+		return err
+	}
+
+	log.Printf("Conta %s suspensa por falta de pagamento da assinatura %s", customer.WHMUsername, subscriptionID)
+	sm.notify(customer.UserID, fmt.Sprintf("Sua hospedagem do domínio %s foi suspensa por falta de pagamento. "+
+		"Assim que o pagamento for confirmado, ela será reativada automaticamente.", customer.Domain))
+	return nil
+}
+
+// SubscriptionCanceled remove a conta do WHM quando a assinatura é encerrada.
+func (sm *StateManager) SubscriptionCanceled(subscriptionID string) error {
+	ctx := context.Background()
+
+	customer, err := sm.subscriptionCustomer(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if customer.AccountStatus == database.AccountStatusTerminated {
+		return nil
+	}
+
+	if err := sm.whmClient.TerminateAccount(customer.WHMUsername); err != nil {
+		return fmt.Errorf("falha ao remover a conta %s no WHM: %w", customer.WHMUsername, err)
+	}
+	if err := sm.dbStore.UpdateCustomer(ctx, customer.UserID, map[string]interface{}{"account_status": database.AccountStatusTerminated}); err != nil {
+		return err
+	}
+
+	log.Printf("Conta %s encerrada após o cancelamento da assinatura %s", customer.WHMUsername, subscriptionID)
+	sm.notify(customer.UserID, fmt.Sprintf("Sua assinatura de hospedagem do domínio %s foi encerrada e a conta foi removida. "+
+		"Se precisar de ajuda, é só chamar por aqui.", customer.Domain))
+	return nil
+}
+
+// SubscriptionPlanChanged troca o pacote da conta no WHM quando o cliente muda de plano.
+func (sm *StateManager) SubscriptionPlanChanged(subscriptionID, productID string) error {
+	ctx := context.Background()
+
+	product, ok := sm.catalog.FindByID(productID)
+	if !ok || !product.IsHostingPlan() {
+		return fmt.Errorf("produto %s não é um plano de hospedagem do catálogo", productID)
+	}
+
+	customer, err := sm.subscriptionCustomer(ctx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if customer.WHMUsername == "" || customer.WHMPackage == product.WHMPackage {
+		return nil
+	}
+
+	if err := sm.whmClient.ChangePackage(customer.WHMUsername, product.WHMPackage); err != nil {
+		return fmt.Errorf("falha ao trocar o pacote da conta %s no WHM: %w", customer.WHMUsername, err)
+	}
+	if err := sm.dbStore.UpdateCustomer(ctx, customer.UserID, map[string]interface{}{"whm_package": product.WHMPackage}); err != nil {
+		return err
+	}
+
+	log.Printf("Conta %s migrada para o pacote %s (assinatura %s)", customer.WHMUsername, product.WHMPackage, subscriptionID)
+	sm.notify(customer.UserID, fmt.Sprintf("Pronto! ✅ A hospedagem do domínio %s agora está no plano %s.", customer.Domain, product.Name))
+	return nil
+}
+
+// notify envia uma mensagem ativa ao cliente, registrando a falha sem interromper o fluxo.
+func (sm *StateManager) notify(userID, message string) {
+	if err := sm.whatsappClient.SendMessage(userID, message); err != nil {
+		log.Printf("AVISO: Falha ao enviar notificação para o usuário %s: %v", userID, err)
+	}
+}
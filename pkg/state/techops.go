@@ -0,0 +1,189 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/techops"
+)
+
+// techOpsRoutes descreve, para cada categoria, a equipe que assume o caso.
+var techOpsRoutes = map[techops.Category]string{
+	techops.Security:     "Segurança da Informação",
+	techops.Architecture: "Arquitetura de Sistemas",
+	techops.LGPD:         "LGPD e Governança de Dados",
+	techops.Performance:  "Performance",
+	techops.Legal:        "Jurídico Digital",
+}
+
+const techOpsDiagnosticPrompt = "Digite OK para contratar o diagnóstico ou MENU para voltar."
+
+// startTechOps apresenta o Tech Ops. Todo projeto começa por um diagnóstico pago,
+// precedido da pré-análise que direciona o caso ao especialista certo.
+func (sm *StateManager) startTechOps(session *UserSession) string {
+	session.ProductID = ""
+	session.PreAnalysis = PreAnalysisData{}
+	session.TechOps = TechOpsData{}
+	session.State = StateTechOpsStart
+	return "Tech Ops 🛠️\n\nConsultoria especializada em segurança da informação, arquitetura de sistemas e LGPD.\n\n" +
+		"Antes do diagnóstico, faremos algumas perguntas rápidas para entender o seu cenário.\n\n" +
+		"Digite OK para começar ou MENU para voltar."
+}
+
+// handleTechOps conduz a pré-análise do Tech Ops, uma pergunta por mensagem.
+func (sm *StateManager) handleTechOps(ctx context.Context, session *UserSession, text string) (string, error) {
+	input := strings.ToLower(strings.TrimSpace(text))
+	if input == "menu" {
+		session.State = StateAwaitingOption
+		return mainMenu, nil
+	}
+
+	switch session.State {
+	case StateTechOpsStart:
+		if input != "ok" {
+			return "Digite OK para começar ou MENU para voltar.", nil
+		}
+		session.State = StateTechOpsLgpdConfirm
+		return "As informações que você enviar serão usadas apenas para a análise do seu caso, conforme a LGPD. Você concorda?\n\n1 - Sim\n2 - Não", nil
+
+	case StateTechOpsLgpdConfirm:
+		switch input {
+		case "1", "sim":
+			session.State = StateTechOpsPreAnalysisRepo
+			return "O sistema tem um repositório de código (GitHub, GitLab, Bitbucket)?\n\n1 - Sim\n2 - Não", nil
+		case "2", "não", "nao":
+			session.State = StateAwaitingOption
+			return "Sem problemas. Sem o consentimento não podemos seguir com a pré-análise.\n\n" + mainMenu, nil
+		}
+		return "Por favor, digite 1 para concordar ou 2 para não concordar.", nil
+
+	case StateTechOpsPreAnalysisRepo:
+		return sm.askLink(session, input, StateTechOpsPreAnalysisRepoLink, "Envie o link do repositório.", askSystemURL), nil
+
+	case StateTechOpsPreAnalysisRepoLink:
+		link, ok := parseLink(text)
+		if !ok {
+			return "Esse link não parece válido. Envie o endereço completo (ex.: https://github.com/empresa/projeto).", nil
+		}
+		session.PreAnalysis.RepoURL = link
+		session.State = StateTechOpsPreAnalysisSite
+		return askSystemURL, nil
+
+	case StateTechOpsPreAnalysisSite:
+		return sm.askLink(session, input, StateTechOpsPreAnalysisSiteLink, "Envie o endereço do sistema.", askProblem), nil
+
+	case StateTechOpsPreAnalysisSiteLink:
+		link, ok := parseLink(text)
+		if !ok {
+			return "Esse endereço não parece válido. Envie o endereço completo (ex.: https://sistema.empresa.com.br).", nil
+		}
+		session.PreAnalysis.SystemURL = link
+		session.State = StateTechOpsPreAnalysisProblem
+		return askProblem, nil
+
+	case StateTechOpsPreAnalysisProblem:
+		description := strings.TrimSpace(text)
+		if len([]rune(description)) < 10 {
+			return "Conte um pouco mais sobre o problema ou objetivo, em uma mensagem.", nil
+		}
+		session.PreAnalysis.ProblemDescription = description
+		return sm.classifyTechOpsLead(ctx, session), nil
+
+	case StateTechOpsClassification:
+		if input != "ok" {
+			return techOpsDiagnosticPrompt, nil
+		}
+		return sm.choosePaymentMethod(ctx, session)
+	}
+
+	return sm.startTechOps(session), nil
+}
+
+const (
+	askSystemURL = "O sistema está publicado em algum endereço na internet?\n\n1 - Sim\n2 - Não"
+	askProblem   = "Em uma mensagem, descreva o principal problema ou objetivo do projeto."
+)
+
+// askLink trata as perguntas de sim/não que antecedem um link da pré-análise.
+func (sm *StateManager) askLink(session *UserSession, input string, linkState State, linkPrompt, nextPrompt string) string {
+	switch input {
+	case "1", "sim":
+		session.State = linkState
+		return linkPrompt
+	case "2", "não", "nao":
+		if linkState == StateTechOpsPreAnalysisRepoLink {
+			session.State = StateTechOpsPreAnalysisSite
+		} else {
+			session.State = StateTechOpsPreAnalysisProblem
+		}
+		return nextPrompt
+	}
+	return "Por favor, digite 1 para sim ou 2 para não."
+}
+
+// classifyTechOpsLead classifica o lead, grava o resultado na sessão e o direciona:
+// o caso vai para a fila da equipe responsável e o cliente segue para o diagnóstico.
+func (sm *StateManager) classifyTechOpsLead(ctx context.Context, session *UserSession) string {
+	lead := techops.Lead{
+		ProblemDescription: session.PreAnalysis.ProblemDescription,
+		RepoURL:            session.PreAnalysis.RepoURL,
+		SystemURL:          session.PreAnalysis.SystemURL,
+	}
+
+	assessment, source := techops.ClassifyKeywords(lead), "keywords"
+	if sm.leads != nil {
+		if result, err := sm.leads.ClassifyLead(ctx, lead); err != nil {
+			log.Printf("AVISO: Falha ao classificar o lead do usuário %s; usando palavras-chave: %v", session.UserID, err)
+		} else {
+			assessment, source = result, "ai"
+		}
+	}
+
+	session.TechOps = TechOpsData{
+		Category:     string(assessment.Category),
+		Urgency:      assessment.Urgency,
+		Complexity:   assessment.Complexity,
+		Summary:      assessment.Summary,
+		Source:       source,
+		ClassifiedAt: time.Now(),
+	}
+	session.State = StateTechOpsClassification
+
+	team := techOpsRoutes[assessment.Category]
+	sm.alertOperators(ctx, "techops_"+string(assessment.Category), session.UserID, fmt.Sprintf(
+		"Lead Tech Ops para %s (urgência %s, complexidade %s): %s | Repositório: %s | Sistema: %s",
+		team, assessment.Urgency, assessment.Complexity, assessment.Summary,
+		valueOrDash(lead.RepoURL), valueOrDash(lead.SystemURL)))
+
+	msg := fmt.Sprintf("Obrigado! Pela sua descrição, o caso fica com a nossa equipe de %s.", team)
+	if assessment.Urgency == techops.LevelCritical {
+		// Incidentes em andamento não esperam o pagamento: a equipe já foi acionada.
+		msg += "\n\n🚨 Como parece haver um incidente em andamento, um especialista vai falar com você por aqui o quanto antes."
+	}
+	return msg + "\n\nO próximo passo é o diagnóstico técnico, em que avaliamos o cenário em detalhes antes de qualquer proposta.\n\n" +
+		techOpsDiagnosticPrompt
+}
+
+// parseLink valida um link enviado pelo cliente, completando o esquema quando ausente.
+func parseLink(text string) (string, bool) {
+	link := strings.TrimSpace(text)
+	if !strings.Contains(link, "://") {
+		link = "https://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || !strings.Contains(u.Host, ".") {
+		return "", false
+	}
+	return u.String(), true
+}
+
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
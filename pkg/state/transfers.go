@@ -0,0 +1,261 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/database"
+	"github.com/dresbach/dresbach-assistente/pkg/domain"
+)
+
+// maxTransferTracking é por quanto tempo a delegação de um domínio é acompanhada
+// antes de o caso ser encerrado e repassado à equipe.
+const maxTransferTracking = 30 * 24 * time.Hour
+
+// authCodePattern aceita os códigos EPP usuais: sem espaços, de 6 a 64 caracteres.
+var authCodePattern = regexp.MustCompile(`^\S{6,64}$`)
+
+// commandWords são respostas da conversa que não podem ser tomadas por um código
+// EPP, embora tenham o formato de um (ex.: "verificar" repetido da etapa anterior).
+var commandWords = map[string]bool{
+	"verificar": true,
+	"cancelar":  true,
+	"voltar":    true,
+	"confirmar": true,
+	"continuar": true,
+	"atendente": true,
+}
+
+// TransferPolicy define o acompanhamento dos domínios que passam a apontar para a hospedagem.
+type TransferPolicy struct {
+	// Interval é o intervalo entre as consultas aos servidores DNS dos domínios.
+	Interval time.Duration
+	// ReminderAfter é o prazo para lembrar o cliente de alterar os servidores DNS.
+	ReminderAfter time.Duration
+}
+
+// transferPrompt explica os próximos passos de um domínio verificado. Fora do .br,
+// o cliente pode enviar o código EPP para transferir também o registro; no .br o
+// domínio continua no Registro.br e só os servidores DNS são alterados.
+func transferPrompt(name string) string {
+	if domain.IsBR(name) {
+		return domainContinuePrompt
+	}
+	return "Se quiser transferir também o registro do domínio para a Dresbach, envie o código de autorização (EPP) " +
+		"fornecido pelo registrador atual. Para manter o registro onde está e apenas apontar os servidores DNS, digite PULAR."
+}
+
+// handleTransfer recebe o código EPP, quando aplicável, e o confirma com o cliente
+// antes de seguir para o pagamento.
+func (sm *StateManager) handleTransfer(ctx context.Context, session *UserSession, input string) (string, error) {
+	normalized := strings.ToLower(input)
+	if normalized == "menu" {
+		// O código EPP cifrado não fica na sessão de quem desistiu da compra.
+		session.Transfer = TransferData{}
+		return leaveSales(session), nil
+	}
+	if domain.IsBR(session.Domain) {
+		if normalized != "ok" {
+			return "Por favor, digite 'OK' para confirmar e continuar ou 'MENU' para voltar.", nil
+		}
+		return sm.choosePaymentMethod(ctx, session)
+	}
+
+	switch {
+	case normalized == "pular" || normalized == "ok":
+		session.Transfer.SealedAuthCode = nil
+		return sm.choosePaymentMethod(ctx, session)
+	case !commandWords[normalized] && authCodePattern.MatchString(input):
+		// O código diferencia maiúsculas de minúsculas, então é cifrado como digitado.
+		sealed, err := sm.credentials.Seal(input)
+		if err != nil {
+			log.Printf("ERRO: Falha ao cifrar o código EPP do usuário %s: %v", session.UserID, err)
+			return "Desculpe, não consegui registrar o código agora. Envie-o novamente ou digite PULAR.", nil
+		}
+		session.Transfer.SealedAuthCode = sealed
+		session.State = StateTechOpsConfirmAuthCode
+		return fmt.Sprintf("Recebi o código de autorização (EPP) `%s`.\n\n"+
+			"Digite SIM para confirmar ou envie o código correto.", input), nil
+	default:
+		return "Não reconheci esse código. Envie o código de autorização (EPP) exatamente como o registrador informou ou digite PULAR.", nil
+	}
+}
+
+// handleConfirmAuthCode confirma o código EPP recebido antes de seguir para o pagamento.
+// Um código novo substitui o anterior e é confirmado da mesma forma.
+func (sm *StateManager) handleConfirmAuthCode(ctx context.Context, session *UserSession, input string) (string, error) {
+	switch strings.ToLower(input) {
+	case "sim", "confirmar":
+		response, err := sm.choosePaymentMethod(ctx, session)
+		return "Código confirmado! 🔑 Solicitaremos a transferência do registro assim que a hospedagem for ativada.\n\n" + response, err
+	case "nao", "não":
+		session.Transfer.SealedAuthCode = nil
+		session.State = StateTechOpsHandleTransfer
+		return "Tudo bem. Envie o código de autorização (EPP) correto ou digite PULAR.", nil
+	}
+	// As demais respostas (PULAR, MENU, outro código) seguem como na etapa do código.
+	session.Transfer.SealedAuthCode = nil
+	session.State = StateTechOpsHandleTransfer
+	return sm.handleTransfer(ctx, session, input)
+}
+
+// sessionTransfer retorna os dados de transferência do domínio escolhido na conversa.
+func (sm *StateManager) sessionTransfer(ctx context.Context, userID, domainName string) TransferData {
+	dbSession, err := sm.dbStore.LoadSession(ctx, userID)
+	if err != nil {
+		log.Printf("AVISO: Falha ao carregar a sessão do usuário %s para o provisionamento: %v", userID, err)
+		return TransferData{}
+	}
+	if dbSession.Domain != domainName {
+		return TransferData{}
+	}
+	return TransferData(dbSession.Transfer)
+}
+
+// attachTransfer copia para o job os dados de transferência do domínio escolhido na conversa.
+func (sm *StateManager) attachTransfer(ctx context.Context, job *database.ProvisioningJob) {
+	transfer := sm.sessionTransfer(ctx, job.UserID, job.Domain)
+	job.Register, job.Transfer, job.SealedAuthCode = transfer.Register, transfer.Requested, transfer.SealedAuthCode
+}
+
+// clearSessionTransfer apaga da sessão os dados de transferência já copiados para o
+// job de provisionamento, para que o código EPP cifrado fique apenas no job.
+func (sm *StateManager) clearSessionTransfer(ctx context.Context, userID, domainName string) {
+	_, err := sm.dbStore.UpdateSession(ctx, userID,
+		map[string]interface{}{"domain": domainName},
+		map[string]interface{}{"transfer": database.TransferData{}})
+	if err != nil {
+		log.Printf("AVISO: Falha ao limpar a transferência da sessão do usuário %s: %v", userID, err)
+	}
+}
+
+// transferDomainStep passa a acompanhar a delegação de um domínio já registrado,
+// orienta o cliente a alterar os servidores DNS e, com o código EPP, pede à equipe
+// a transferência do registro. Domínios registrados conosco não têm o que fazer aqui.
+func (sm *StateManager) transferDomainStep(ctx context.Context, job *database.ProvisioningJob) error {
+	if !job.Transfer {
+		return nil
+	}
+
+	transfer := &database.DomainTransfer{
+		Domain:      job.Domain,
+		UserID:      job.UserID,
+		Username:    job.Username,
+		Nameservers: sm.nameservers,
+		Status:      database.TransferStatusPending,
+		RequestedAt: time.Now(),
+	}
+	if err := sm.dbStore.SaveDomainTransfer(ctx, transfer); err != nil {
+		return err
+	}
+	if err := sm.whatsappClient.SendMessage(job.UserID, nameserverInstructions(job.Domain, sm.nameservers)); err != nil {
+		return err
+	}
+
+	if len(job.SealedAuthCode) > 0 {
+		// O código não vai para o resumo nem para o log: a equipe o abre por um link de uso único.
+		link, expiresAt, err := sm.credentials.IssueAuthCode(ctx, job.UserID, job.Domain, job.SealedAuthCode)
+		if err != nil {
+			return fmt.Errorf("falha ao emitir o link do código EPP do domínio %s: %w", job.Domain, err)
+		}
+		sm.alertOperatorsWithLink(ctx, "domain_transfer", job.UserID, fmt.Sprintf(
+			"Solicitar a transferência do registro do domínio %s (conta %s). O código EPP está no link da tarefa, válido até %s",
+			job.Domain, job.Username, formatDeadline(expiresAt)), link)
+		job.SealedAuthCode = nil
+	}
+	return nil
+}
+
+// nameserverInstructions orienta o cliente a apontar o domínio para a hospedagem.
+func nameserverInstructions(name string, nameservers []string) string {
+	panel := "no painel do registrador do domínio"
+	if domain.IsBR(name) {
+		panel = "no Registro.br (registro.br > Domínios > DNS > Alterar servidores DNS)"
+	}
+	return fmt.Sprintf("🌐 Para o domínio %s funcionar na sua nova hospedagem, altere os servidores DNS %s para:\n\n%s\n\n"+
+		"A propagação pode levar algumas horas. Acompanharemos por aqui e avisaremos você assim que o domínio estiver no ar.",
+		domain.Display(name), panel, strings.Join(nameservers, "\n"))
+}
+
+// RunDomainTransfers verifica periodicamente a delegação dos domínios transferidos
+// até que o contexto seja cancelado.
+func (sm *StateManager) RunDomainTransfers(ctx context.Context, policy TransferPolicy) {
+	ticker := time.NewTicker(policy.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sm.checkDomainTransfers(ctx, policy)
+		}
+	}
+}
+
+// checkDomainTransfers avisa os clientes cujos domínios já apontam para a hospedagem,
+// lembra os que ainda não alteraram os servidores DNS e encerra os casos antigos.
+func (sm *StateManager) checkDomainTransfers(ctx context.Context, policy TransferPolicy) {
+	transfers, err := sm.dbStore.PendingDomainTransfers(ctx)
+	if err != nil {
+		log.Printf("ERRO: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for i := range transfers {
+		transfer := &transfers[i]
+		delegated, current, err := sm.ownership.DelegatedTo(ctx, transfer.Domain, transfer.Nameservers)
+		if err != nil {
+			log.Printf("AVISO: %v", err)
+			continue
+		}
+		transfer.CheckedAt = now
+		transfer.CurrentNameservers = current
+
+		var msg string
+		switch {
+		case delegated:
+			transfer.Status = database.TransferStatusLive
+			transfer.LiveAt = now
+			// A emissão feita no provisionamento falha enquanto o domínio aponta para outro servidor.
+			if err := sm.whmClient.StartAutoSSL(transfer.Username); err != nil {
+				log.Printf("AVISO: Falha ao iniciar o AutoSSL da conta %s: %v", transfer.Username, err)
+			}
+			msg = fmt.Sprintf("🎉 O domínio %s já está apontando para a Dresbach e está no ar na sua nova hospedagem!",
+				domain.Display(transfer.Domain))
+		case now.Sub(transfer.RequestedAt) >= maxTransferTracking:
+			transfer.Status = database.TransferStatusAbandoned
+			sm.alertOperators(ctx, "domain_transfer", transfer.UserID, fmt.Sprintf(
+				"O domínio %s (conta %s) ainda não aponta para os nossos servidores DNS após %s. Servidores atuais: %s",
+				transfer.Domain, transfer.Username, maxTransferTracking, strings.Join(current, ", ")))
+		case transfer.ReminderSentAt.IsZero() && now.Sub(transfer.RequestedAt) >= policy.ReminderAfter:
+			transfer.ReminderSentAt = now
+			msg = delegationReminder(transfer)
+		}
+
+		if err := sm.dbStore.SaveDomainTransfer(ctx, transfer); err != nil {
+			log.Printf("AVISO: %v", err)
+			continue
+		}
+		if msg != "" {
+			sm.notify(transfer.UserID, msg)
+		}
+	}
+}
+
+// delegationReminder lembra o cliente dos servidores DNS, mostrando os que estão em uso.
+func delegationReminder(transfer *database.DomainTransfer) string {
+	current := "nenhum servidor encontrado"
+	if len(transfer.CurrentNameservers) > 0 {
+		current = strings.Join(transfer.CurrentNameservers, "\n")
+	}
+	return fmt.Sprintf("O domínio %s ainda não aponta para a sua hospedagem na Dresbach.\n\n"+
+		"Servidores DNS atuais:\n%s\n\nServidores DNS corretos:\n%s\n\n"+
+		"Se precisar de ajuda para fazer a alteração, é só responder por aqui.",
+		domain.Display(transfer.Domain), current, strings.Join(transfer.Nameservers, "\n"))
+}
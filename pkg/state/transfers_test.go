@@ -0,0 +1,75 @@
+package state
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/dresbach/dresbach-assistente/pkg/credentials"
+)
+
+func newTransferManager(t *testing.T) *StateManager {
+	t.Helper()
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+	service, err := credentials.NewService(nil, key, "https://exemplo.com", 0)
+	if err != nil {
+		t.Fatalf("NewService: %v", err)
+	}
+	return &StateManager{credentials: service}
+}
+
+func TestHandleTransfer(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     State
+		input     string
+		wantState State
+		wantCode  string
+	}{
+		{name: "palavra de comando", state: StateTechOpsHandleTransfer, input: "verificar", wantState: StateTechOpsHandleTransfer},
+		{name: "palavra de comando em maiúsculas", state: StateTechOpsHandleTransfer, input: "Cancelar", wantState: StateTechOpsHandleTransfer},
+		{name: "código pede confirmação", state: StateTechOpsHandleTransfer, input: "AbC-123xyz", wantState: StateTechOpsConfirmAuthCode, wantCode: "AbC-123xyz"},
+		{name: "menu na etapa do código", state: StateTechOpsHandleTransfer, input: "menu", wantState: StateAwaitingOption},
+		{name: "código recusado", state: StateTechOpsConfirmAuthCode, input: "não", wantState: StateTechOpsHandleTransfer},
+		{name: "código corrigido", state: StateTechOpsConfirmAuthCode, input: "XyZ-999abc", wantState: StateTechOpsConfirmAuthCode, wantCode: "XyZ-999abc"},
+		{name: "menu na confirmação", state: StateTechOpsConfirmAuthCode, input: "MENU", wantState: StateAwaitingOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := newTransferManager(t)
+			previous, err := sm.credentials.Seal("codigo-anterior")
+			if err != nil {
+				t.Fatalf("Seal: %v", err)
+			}
+			session := &UserSession{
+				UserID:   "5511999999999",
+				State:    tt.state,
+				Domain:   "meusite.com",
+				Transfer: TransferData{Requested: true},
+			}
+			if tt.state == StateTechOpsConfirmAuthCode {
+				session.Transfer.SealedAuthCode = previous
+				_, err = sm.handleConfirmAuthCode(context.Background(), session, tt.input)
+			} else {
+				_, err = sm.handleTransfer(context.Background(), session, tt.input)
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if session.State != tt.wantState {
+				t.Errorf("estado = %q, esperava %q", session.State, tt.wantState)
+			}
+
+			code := ""
+			if session.Transfer.SealedAuthCode != nil {
+				if code, err = sm.credentials.Open(session.Transfer.SealedAuthCode); err != nil {
+					t.Fatalf("Open: %v", err)
+				}
+			}
+			if code != tt.wantCode {
+				t.Errorf("código = %q, esperava %q", code, tt.wantCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package stripe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/charge"
+	"github.com/stripe/stripe-go/v72/invoice"
+)
+
+// Invoice é a visão simplificada de uma fatura da Stripe usada na Área do Cliente.
+type Invoice struct {
+	ID        string
+	Number    string
+	Status    string
+	Amount    int64
+	Currency  string
+	CreatedAt time.Time
+	// PDFURL aponta para o PDF da fatura. Nunca expomos o HostedInvoiceURL,
+	// pois para faturas em aberto ele é uma página de pagamento.
+	PDFURL string
+}
+
+// Receipt é a visão simplificada de um recibo (cobrança) da Stripe.
+type Receipt struct {
+	ChargeID    string
+	Description string
+	Amount      int64
+	Currency    string
+	CreatedAt   time.Time
+	ReceiptURL  string
+	// InvoiceID é preenchido quando a cobrança pertence a uma fatura.
+	InvoiceID string
+}
+
+// ListInvoices retorna as faturas mais recentes de um cliente da Stripe.
+func (c *Client) ListInvoices(customerID string, limit int) ([]Invoice, error) {
+	params := &stripe.InvoiceListParams{Customer: stripe.String(customerID)}
+	params.Limit = stripe.Int64(int64(limit))
+
+	var invoices []Invoice
+	it := invoice.List(params)
+	for it.Next() && len(invoices) < limit {
+		inv := it.Invoice()
+		invoices = append(invoices, Invoice{
+			ID:        inv.ID,
+			Number:    inv.Number,
+			Status:    string(inv.Status),
+			Amount:    inv.Total,
+			Currency:  string(inv.Currency),
+			CreatedAt: time.Unix(inv.Created, 0),
+			PDFURL:    inv.InvoicePDF,
+		})
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao listar faturas na Stripe: %w", err)
+	}
+	return invoices, nil
+}
+
+// ListReceipts retorna os recibos das cobranças pagas mais recentes de um cliente da Stripe.
+func (c *Client) ListReceipts(customerID string, limit int) ([]Receipt, error) {
+	params := &stripe.ChargeListParams{Customer: stripe.String(customerID)}
+	params.Limit = stripe.Int64(int64(limit))
+
+	var receipts []Receipt
+	it := charge.List(params)
+	for it.Next() && len(receipts) < limit {
+		ch := it.Charge()
+		if !ch.Paid || ch.ReceiptURL == "" {
+			continue
+		}
+		receipt := Receipt{
+			ChargeID:    ch.ID,
+			Description: ch.Description,
+			Amount:      ch.Amount,
+			Currency:    string(ch.Currency),
+			CreatedAt:   time.Unix(ch.Created, 0),
+			ReceiptURL:  ch.ReceiptURL,
+		}
+		if ch.Invoice != nil {
+			receipt.InvoiceID = ch.Invoice.ID
+		}
+		receipts = append(receipts, receipt)
+	}
+	if err := it.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao listar recibos na Stripe: %w", err)
+	}
+	return receipts, nil
+}
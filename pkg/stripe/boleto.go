@@ -0,0 +1,63 @@
+package stripe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/paymentintent"
+	"github.com/stripe/stripe-go/v72/sub"
+)
+
+// BoletoDetails contém os dados do boleto gerado no checkout.
+type BoletoDetails struct {
+	Number           string // Linha digitável
+	PDFURL           string
+	HostedVoucherURL string
+	ExpiresAt        time.Time
+}
+
+// checkoutPaymentIntent busca o PaymentIntent de uma sessão de checkout. No modo
+// assinatura, o pagamento pertence à primeira fatura da assinatura.
+func (c *Client) checkoutPaymentIntent(session *stripe.CheckoutSession) (*stripe.PaymentIntent, error) {
+	switch {
+	case session.PaymentIntent != nil:
+		pi, err := paymentintent.Get(session.PaymentIntent.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao buscar o PaymentIntent %s na Stripe: %w", session.PaymentIntent.ID, err)
+		}
+		return pi, nil
+
+	case session.Subscription != nil:
+		params := &stripe.SubscriptionParams{}
+		params.AddExpand("latest_invoice.payment_intent")
+		s, err := sub.Get(session.Subscription.ID, params)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao buscar a assinatura %s na Stripe: %w", session.Subscription.ID, err)
+		}
+		if s.LatestInvoice == nil || s.LatestInvoice.PaymentIntent == nil {
+			return nil, fmt.Errorf("a assinatura %s não possui pagamento pendente", s.ID)
+		}
+		return s.LatestInvoice.PaymentIntent, nil
+	}
+	return nil, fmt.Errorf("a sessão %s não possui PaymentIntent", session.ID)
+}
+
+// boletoDetails retorna os dados do boleto de uma sessão de checkout aguardando pagamento.
+func (c *Client) boletoDetails(session *stripe.CheckoutSession) (*BoletoDetails, error) {
+	pi, err := c.checkoutPaymentIntent(session)
+	if err != nil {
+		return nil, err
+	}
+	if pi.NextAction == nil || pi.NextAction.BoletoDisplayDetails == nil {
+		return nil, fmt.Errorf("o PaymentIntent %s não possui boleto pendente", pi.ID)
+	}
+
+	details := pi.NextAction.BoletoDisplayDetails
+	return &BoletoDetails{
+		Number:           details.Number,
+		PDFURL:           details.PDF,
+		HostedVoucherURL: details.HostedVoucherURL,
+		ExpiresAt:        time.Unix(details.ExpiresAt, 0),
+	}, nil
+}
@@ -0,0 +1,68 @@
+package stripe
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/dresbach/dresbach-assistente/pkg/products"
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/price"
+	"github.com/stripe/stripe-go/v72/product"
+)
+
+// LoadProducts carrega os produtos e preços ativos da Stripe para o catálogo.
+// O preço padrão de cada produto é colocado em primeiro lugar e o pacote do WHM
+// é lido do metadado "whm_package" do produto.
+func (c *Client) LoadProducts() ([]products.Product, error) {
+	var list []products.Product
+	defaultPrices := make(map[string]string)
+
+	productIt := product.List(&stripe.ProductListParams{Active: stripe.Bool(true)})
+	for productIt.Next() {
+		p := productIt.Product()
+		list = append(list, products.Product{
+			ID:          p.ID,
+			Name:        p.Name,
+			Description: p.Description,
+			Type:        string(p.Type),
+			CreatedAt:   time.Unix(p.Created, 0).UTC(),
+			WHMPackage:  p.Metadata["whm_package"],
+		})
+		if p.DefaultPrice != nil {
+			defaultPrices[p.ID] = p.DefaultPrice.ID
+		}
+	}
+	if err := productIt.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao listar produtos na Stripe: %w", err)
+	}
+
+	prices := make(map[string][]products.Price)
+	priceIt := price.List(&stripe.PriceListParams{Active: stripe.Bool(true)})
+	for priceIt.Next() {
+		p := priceIt.Price()
+		if p.Product == nil {
+			continue
+		}
+		entry := products.Price{
+			ID:       p.ID,
+			Amount:   p.UnitAmount,
+			Currency: string(p.Currency),
+		}
+		if p.Recurring != nil {
+			entry.Interval = string(p.Recurring.Interval)
+		}
+		if defaultPrices[p.Product.ID] == p.ID {
+			prices[p.Product.ID] = append([]products.Price{entry}, prices[p.Product.ID]...)
+		} else {
+			prices[p.Product.ID] = append(prices[p.Product.ID], entry)
+		}
+	}
+	if err := priceIt.Err(); err != nil {
+		return nil, fmt.Errorf("falha ao listar preços na Stripe: %w", err)
+	}
+
+	for i := range list {
+		list[i].Prices = prices[list[i].ID]
+	}
+	return list, nil
+}
@@ -1,29 +1,79 @@
-
 package stripe
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/dresbach/dresbach-assistente/pkg/products"
 	"github.com/stripe/stripe-go/v72"
 	"github.com/stripe/stripe-go/v72/checkout/session"
+	"github.com/stripe/stripe-go/v72/price"
+	"github.com/stripe/stripe-go/v72/product"
 )
 
 // Client é um cliente para a API da Stripe.
 type Client struct {
-	SecretKey string
+	SecretKey  string
+	SuccessURL string
+	CancelURL  string
+	// PixExpiration é o prazo para pagar uma cobrança Pix (padrão: 30 minutos).
+	PixExpiration time.Duration
+	// CheckoutExpiration é a validade do link de checkout (entre 30 minutos e 24 horas).
+	CheckoutExpiration time.Duration
 }
 
+// Limites da Stripe para a validade de uma sessão de checkout.
+const (
+	minCheckoutExpiration = 30 * time.Minute
+	maxCheckoutExpiration = 24 * time.Hour
+)
+
 // NewClient cria um novo cliente Stripe.
-func NewClient(secretKey string) *Client {
+func NewClient(secretKey, successURL, cancelURL string) *Client {
 	stripe.Key = secretKey
-	return &Client{SecretKey: secretKey}
+	return &Client{
+		SecretKey:  secretKey,
+		SuccessURL: successURL,
+		CancelURL:  cancelURL,
+	}
 }
 
-// CreateCheckoutSession cria uma sessão de checkout na Stripe e retorna a URL.
-func (c *Client) CreateCheckoutSession(userID, domain string) (string, error) {
-	priceID := "price_1P8cysRxx94s4qFjJbAQS5nJ" // SUBSTITUA PELO SEU PRICE ID REAL
-	successURL := "https://dresbachhosting.com.br/sucesso" // SUBSTITUA PELA SUA URL DE SUCESSO
-	cancelURL := "https://dresbachhosting.com.br/cancelamento"  // SUBSTITUA PELA SUA URL DE CANCELAMENTO
+// CheckoutParams define os dados de uma sessão de checkout.
+type CheckoutParams struct {
+	UserID string
+	Domain string
+	// CustomerID é o cliente da Stripe já vinculado ao usuário. Se estiver vazio,
+	// a Stripe cria um novo cliente, que é vinculado ao nosso cadastro quando o
+	// webhook de conclusão chega.
+	CustomerID string
+	Product    products.Product
+}
+
+// CheckoutLink é uma sessão de checkout criada para o cliente.
+type CheckoutLink struct {
+	ID        string
+	URL       string
+	ExpiresAt time.Time
+}
+
+// CreateCheckoutSession cria uma sessão de checkout na Stripe para o produto escolhido e retorna o link.
+// Produtos com preço recorrente (planos de hospedagem) geram uma assinatura;
+// os demais, um pagamento avulso.
+func (c *Client) CreateCheckoutSession(checkout CheckoutParams) (*CheckoutLink, error) {
+	// Usa o preço já carregado no catálogo; se não houver, consulta a Stripe.
+	linePrice, ok := checkout.Product.DefaultPrice()
+	if !ok {
+		var err error
+		if linePrice, err = c.ResolvePrice(checkout.Product.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	metadata := map[string]string{
+		"user_id":    checkout.UserID,
+		"domain":     checkout.Domain,
+		"product_id": checkout.Product.ID,
+	}
 
 	params := &stripe.CheckoutSessionParams{
 		PaymentMethodTypes: stripe.StringSlice([]string{
@@ -32,31 +82,101 @@ func (c *Client) CreateCheckoutSession(userID, domain string) (string, error) {
 		}),
 		LineItems: []*stripe.CheckoutSessionLineItemParams{
 			{
-				Price:    stripe.String(priceID),
+				Price:    stripe.String(linePrice.ID),
 				Quantity: stripe.Int64(1),
 			},
 		},
-		Mode:       stripe.String(string(stripe.CheckoutSessionModePayment)),
-		SuccessURL: stripe.String(successURL),
-		CancelURL:  stripe.String(cancelURL),
-		// Anexa os metadados ao PaymentIntent, que é o objeto de pagamento subjacente.
-		// Isso resolve o erro de compilação "unknown field Metadata".
-		PaymentIntentData: &stripe.CheckoutSessionPaymentIntentDataParams{
-			Metadata: map[string]string{
-				"user_id": userID,
-				"domain":  domain,
-			},
-		},
+		SuccessURL: stripe.String(c.SuccessURL),
+		CancelURL:  stripe.String(c.CancelURL),
+		ExpiresAt:  stripe.Int64(time.Now().Add(c.checkoutExpiration()).Unix()),
+	}
+
+	if linePrice.Interval != "" {
+		params.Mode = stripe.String(string(stripe.CheckoutSessionModeSubscription))
+		// Os metadados ficam na assinatura para identificar o usuário nas renovações.
+		params.SubscriptionData = &stripe.CheckoutSessionSubscriptionDataParams{
+			Metadata: metadata,
+		}
+	} else {
+		params.Mode = stripe.String(string(stripe.CheckoutSessionModePayment))
+		// Anexa os metadados também ao PaymentIntent, que aparece nos eventos de pagamento.
+		params.PaymentIntentData = &stripe.CheckoutSessionPaymentIntentDataParams{
+			Metadata: metadata,
+		}
+	}
+
+	// Os metadados da própria sessão são os que o webhook lê nos eventos checkout.session.*.
+	for key, value := range metadata {
+		params.AddMetadata(key, value)
+	}
+	params.AddMetadata("product_name", checkout.Product.Name)
+
+	if checkout.CustomerID != "" {
+		params.Customer = stripe.String(checkout.CustomerID)
+	} else if linePrice.Interval == "" {
+		// No modo assinatura a Stripe sempre cria o cliente.
+		params.CustomerCreation = stripe.String(string(stripe.CheckoutSessionCustomerCreationAlways))
 	}
 
 	// Expande o objeto PaymentIntent no retorno da sessão para que o webhook
 	// não precise fazer uma chamada extra à API para obter os metadados.
-	params.AddExpand("payment_intent")
+	if linePrice.Interval == "" {
+		params.AddExpand("payment_intent")
+	}
 
 	s, err := session.New(params)
 	if err != nil {
-		return "", fmt.Errorf("falha ao criar a sessão de checkout na Stripe: %w", err)
+		return nil, fmt.Errorf("falha ao criar a sessão de checkout na Stripe: %w", err)
+	}
+
+	return &CheckoutLink{ID: s.ID, URL: s.URL, ExpiresAt: time.Unix(s.ExpiresAt, 0)}, nil
+}
+
+// checkoutExpiration retorna a validade do link de checkout dentro dos limites da Stripe.
+func (c *Client) checkoutExpiration() time.Duration {
+	switch {
+	case c.CheckoutExpiration <= 0 || c.CheckoutExpiration > maxCheckoutExpiration:
+		return maxCheckoutExpiration
+	case c.CheckoutExpiration < minCheckoutExpiration:
+		return minCheckoutExpiration
+	}
+	return c.CheckoutExpiration
+}
+
+// ResolvePrice retorna o preço ativo de um produto na Stripe, dando preferência
+// ao preço padrão configurado no produto.
+func (c *Client) ResolvePrice(productID string) (products.Price, error) {
+	prod, err := product.Get(productID, nil)
+	if err != nil {
+		return products.Price{}, fmt.Errorf("falha ao buscar o produto %s na Stripe: %w", productID, err)
+	}
+
+	params := &stripe.PriceListParams{
+		Product: stripe.String(productID),
+		Active:  stripe.Bool(true),
 	}
 
-	return s.URL, nil
+	var resolved products.Price
+	it := price.List(params)
+	for it.Next() {
+		p := it.Price()
+		isDefault := prod.DefaultPrice != nil && p.ID == prod.DefaultPrice.ID
+		if resolved.ID != "" && !isDefault {
+			continue
+		}
+		resolved = products.Price{ID: p.ID, Amount: p.UnitAmount, Currency: string(p.Currency)}
+		if p.Recurring != nil {
+			resolved.Interval = string(p.Recurring.Interval)
+		}
+		if isDefault {
+			break
+		}
+	}
+	if err := it.Err(); err != nil {
+		return products.Price{}, fmt.Errorf("falha ao listar os preços do produto %s na Stripe: %w", productID, err)
+	}
+	if resolved.ID == "" {
+		return products.Price{}, fmt.Errorf("o produto %s não possui preço ativo na Stripe", productID)
+	}
+	return resolved, nil
 }
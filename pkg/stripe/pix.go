@@ -0,0 +1,99 @@
+package stripe
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/paymentintent"
+)
+
+// defaultPixExpiration é o prazo de pagamento do Pix quando o cliente não define PixExpiration.
+const defaultPixExpiration = 30 * time.Minute
+
+// paymentChannelPix identifica, nos metadados do PaymentIntent, as cobranças Pix
+// criadas diretamente pelo assistente (fora do Checkout).
+const paymentChannelPix = "pix"
+
+// PixCharge contém os dados para o cliente pagar uma cobrança Pix.
+type PixCharge struct {
+	PaymentIntentID string
+	QRCodeImageURL  string // Imagem PNG do QR Code
+	CopyPasteCode   string // Código "Pix copia e cola"
+	ExpiresAt       time.Time
+}
+
+// pixDisplayQRCode reflete next_action.pix_display_qr_code, que não existe na versão
+// da biblioteca stripe-go usada pelo projeto e por isso é lido do JSON bruto.
+type pixDisplayQRCode struct {
+	NextAction struct {
+		PixDisplayQRCode struct {
+			Data        string `json:"data"`
+			ExpiresAt   int64  `json:"expires_at"`
+			ImageURLPNG string `json:"image_url_png"`
+		} `json:"pix_display_qr_code"`
+	} `json:"next_action"`
+}
+
+// CreatePixPayment cria e confirma uma cobrança Pix para o produto escolhido.
+// Pix não aceita cobrança recorrente, então apenas produtos com preço avulso são suportados.
+func (c *Client) CreatePixPayment(checkout CheckoutParams) (*PixCharge, error) {
+	linePrice, ok := checkout.Product.DefaultPrice()
+	if !ok {
+		var err error
+		if linePrice, err = c.ResolvePrice(checkout.Product.ID); err != nil {
+			return nil, err
+		}
+	}
+	if linePrice.Interval != "" {
+		return nil, fmt.Errorf("o produto %s tem cobrança recorrente e não pode ser pago via Pix", checkout.Product.ID)
+	}
+
+	expiration := c.PixExpiration
+	if expiration <= 0 {
+		expiration = defaultPixExpiration
+	}
+
+	params := &stripe.PaymentIntentParams{
+		Amount:             stripe.Int64(linePrice.Amount),
+		Currency:           stripe.String(linePrice.Currency),
+		PaymentMethodTypes: stripe.StringSlice([]string{"pix"}),
+		Confirm:            stripe.Bool(true),
+		Description:        stripe.String(checkout.Product.Name),
+	}
+	if checkout.CustomerID != "" {
+		params.Customer = stripe.String(checkout.CustomerID)
+	}
+	params.AddExtra("payment_method_data[type]", "pix")
+	params.AddExtra("payment_method_options[pix][expires_after_seconds]", strconv.Itoa(int(expiration.Seconds())))
+	params.AddMetadata("user_id", checkout.UserID)
+	params.AddMetadata("domain", checkout.Domain)
+	params.AddMetadata("product_id", checkout.Product.ID)
+	params.AddMetadata("channel", paymentChannelPix)
+
+	pi, err := paymentintent.New(params)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar a cobrança Pix na Stripe: %w", err)
+	}
+
+	var raw pixDisplayQRCode
+	if pi.LastResponse == nil {
+		return nil, fmt.Errorf("resposta da Stripe sem os dados do Pix (PaymentIntent %s)", pi.ID)
+	}
+	if err := json.Unmarshal(pi.LastResponse.RawJSON, &raw); err != nil {
+		return nil, fmt.Errorf("falha ao decodificar os dados do Pix (PaymentIntent %s): %w", pi.ID, err)
+	}
+	qr := raw.NextAction.PixDisplayQRCode
+	if qr.Data == "" {
+		return nil, fmt.Errorf("a Stripe não retornou o QR Code do Pix (PaymentIntent %s)", pi.ID)
+	}
+
+	return &PixCharge{
+		PaymentIntentID: pi.ID,
+		QRCodeImageURL:  qr.ImageURLPNG,
+		CopyPasteCode:   qr.Data,
+		ExpiresAt:       time.Unix(qr.ExpiresAt, 0),
+	}, nil
+}
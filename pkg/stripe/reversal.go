@@ -0,0 +1,132 @@
+package stripe
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/charge"
+	"github.com/stripe/stripe-go/v72/paymentintent"
+)
+
+// Tipos de estorno de um pagamento.
+const (
+	ReversalRefund  = "refund"
+	ReversalDispute = "dispute"
+)
+
+// PaymentReversal descreve um pagamento estornado ou contestado.
+type PaymentReversal struct {
+	Kind     string // ReversalRefund ou ReversalDispute
+	ChargeID string
+	// UserID e ProductID vêm dos metadados do pagamento e ficam vazios nas
+	// renovações de assinatura; nesse caso o cliente é identificado pela Stripe.
+	UserID           string
+	ProductID        string
+	StripeCustomerID string
+	SubscriptionID   string
+	Amount           int64
+	Currency         string
+	Reason           string
+	// Full indica que o valor total foi estornado. Contestações são sempre totais.
+	Full bool
+}
+
+// ReversalHandler define a interface para desfazer o que foi entregue quando
+// um pagamento é estornado ou contestado.
+type ReversalHandler interface {
+	PaymentReversed(reversal PaymentReversal) error
+}
+
+// handleChargeRefunded trata o estorno, total ou parcial, de um pagamento.
+func (h *WebhookHandler) handleChargeRefunded(event stripe.Event) int {
+	var ch stripe.Charge
+	if err := json.Unmarshal(event.Data.Raw, &ch); err != nil {
+		log.Printf("ERRO: Falha ao decodificar a cobrança do evento da Stripe: %v", err)
+		return http.StatusBadRequest
+	}
+	if h.Reversals == nil {
+		return http.StatusOK
+	}
+
+	reversal := PaymentReversal{
+		Kind:     ReversalRefund,
+		Amount:   ch.AmountRefunded,
+		Currency: string(ch.Currency),
+		Full:     ch.Refunded,
+	}
+	if ch.Refunds != nil && len(ch.Refunds.Data) > 0 {
+		reversal.Reason = string(ch.Refunds.Data[0].Reason)
+	}
+	return h.reversePayment(&ch, reversal)
+}
+
+// handleDisputeCreated trata a contestação (chargeback) de um pagamento.
+func (h *WebhookHandler) handleDisputeCreated(event stripe.Event) int {
+	var dispute stripe.Dispute
+	if err := json.Unmarshal(event.Data.Raw, &dispute); err != nil {
+		log.Printf("ERRO: Falha ao decodificar a contestação do evento da Stripe: %v", err)
+		return http.StatusBadRequest
+	}
+	if h.Reversals == nil || dispute.Charge == nil {
+		return http.StatusOK
+	}
+
+	// O evento traz apenas o ID da cobrança contestada.
+	ch, err := charge.Get(dispute.Charge.ID, nil)
+	if err != nil {
+		log.Printf("ERRO: Falha ao buscar a cobrança %s na Stripe: %v", dispute.Charge.ID, err)
+		return http.StatusInternalServerError
+	}
+
+	return h.reversePayment(ch, PaymentReversal{
+		Kind:     ReversalDispute,
+		Amount:   dispute.Amount,
+		Currency: string(dispute.Currency),
+		Reason:   string(dispute.Reason),
+		Full:     true,
+	})
+}
+
+// reversePayment identifica o cliente e o produto da cobrança e repassa o estorno.
+func (h *WebhookHandler) reversePayment(ch *stripe.Charge, reversal PaymentReversal) int {
+	reversal.ChargeID = ch.ID
+	if ch.Customer != nil {
+		reversal.StripeCustomerID = ch.Customer.ID
+	}
+	if ch.Invoice != nil && ch.Invoice.Subscription != nil {
+		reversal.SubscriptionID = ch.Invoice.Subscription.ID
+	}
+
+	if ch.PaymentIntent != nil {
+		metadata, err := paymentMetadata(ch.PaymentIntent)
+		if err != nil {
+			log.Printf("ERRO: %v", err)
+			return http.StatusInternalServerError
+		}
+		reversal.UserID = metadata["user_id"]
+		reversal.ProductID = metadata["product_id"]
+	}
+
+	log.Printf("PAGAMENTO ESTORNADO (%s) na cobrança %s: usuário %q, cliente Stripe %q", reversal.Kind, ch.ID, reversal.UserID, reversal.StripeCustomerID)
+	if err := h.Reversals.PaymentReversed(reversal); err != nil {
+		log.Printf("ERRO: Falha ao tratar o estorno da cobrança %s: %v", ch.ID, err)
+		return http.StatusInternalServerError
+	}
+	return http.StatusOK
+}
+
+// paymentMetadata retorna os metadados do PaymentIntent, buscando-o na Stripe
+// quando o evento traz apenas o ID.
+func paymentMetadata(pi *stripe.PaymentIntent) (map[string]string, error) {
+	if pi.Metadata != nil {
+		return pi.Metadata, nil
+	}
+	full, err := paymentintent.Get(pi.ID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao buscar o PaymentIntent %s na Stripe: %w", pi.ID, err)
+	}
+	return full.Metadata, nil
+}
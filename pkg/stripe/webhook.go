@@ -2,24 +2,76 @@
 package stripe
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
 
 	"github.com/stripe/stripe-go/v72"
+	"github.com/stripe/stripe-go/v72/sub"
 	"github.com/stripe/stripe-go/v72/webhook"
 )
 
 // AccountProvisioner define a interface para provisionar uma conta após o pagamento.
 // Esta é a única dependência que o pacote Stripe tem com o mundo exterior.
 type AccountProvisioner interface {
-	ProvisionAccount(userID, domain, contactEmail string) error
+	ProvisionAccount(userID, domain, contactEmail, productID string) error
+}
+
+// CustomerLinker define a interface para vincular um cliente da Stripe ao nosso cadastro.
+type CustomerLinker interface {
+	LinkStripeCustomer(userID, stripeCustomerID, email string) error
+}
+
+// SubscriptionManager define a interface que conduz o ciclo de vida da conta
+// de hospedagem a partir dos eventos da assinatura.
+type SubscriptionManager interface {
+	LinkSubscription(userID, subscriptionID string) error
+	SubscriptionPaid(subscriptionID string) error
+	SubscriptionPaymentFailed(subscriptionID string, finalAttempt bool) error
+	SubscriptionCanceled(subscriptionID string) error
+	SubscriptionPlanChanged(subscriptionID, productID string) error
+}
+
+// ErrSubscriptionNotFound é retornado pelo SubscriptionManager quando a assinatura
+// não está vinculada a nenhum cliente.
+var ErrSubscriptionNotFound = errors.New("assinatura sem cliente vinculado")
+
+// PaymentNotifier define a interface para avisar o cliente sobre pagamentos
+// assíncronos (boleto) que ainda não foram compensados ou que falharam, e sobre
+// links de checkout que expiraram sem pagamento.
+type PaymentNotifier interface {
+	PaymentPending(userID string, boleto *BoletoDetails) error
+	PaymentFailed(userID string) error
+	CheckoutExpired(userID, checkoutSessionID string) error
+}
+
+// EventLedger define a interface do registro de eventos processados, que torna o
+// webhook idempotente quando a Stripe reenvia um evento.
+type EventLedger interface {
+	ClaimStripeEvent(ctx context.Context, eventID, eventType string) (bool, error)
+	FinishStripeEvent(ctx context.Context, eventID string, procErr error) error
+}
+
+// ProvisioningQueue define a interface para reagendar um provisionamento que
+// falhou, sem que a Stripe precise reenviar o evento.
+type ProvisioningQueue interface {
+	EnqueueProvisioning(userID, domain, contactEmail, productID string, cause error) error
 }
 
 // WebhookHandler lida com os webhooks da Stripe.
 type WebhookHandler struct {
+	Client              *Client
 	Provisioner         AccountProvisioner
+	Customers           CustomerLinker      // Opcional
+	Subscriptions       SubscriptionManager // Opcional
+	Payments            PaymentNotifier     // Opcional
+	Events              EventLedger         // Opcional
+	Retries             ProvisioningQueue   // Opcional
+	Reversals           ReversalHandler     // Opcional
 	StripeWebhookSecret string
 }
 
@@ -43,47 +95,359 @@ func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if event.Type == "checkout.session.completed" {
-		var session stripe.CheckoutSession
-		if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
-			log.Printf("ERRO: Falha ao decodificar a sessão do evento da Stripe: %v", err)
-			w.WriteHeader(http.StatusBadRequest)
-			return
+	if h.Events == nil {
+		w.WriteHeader(h.handleEvent(event))
+		return
+	}
+
+	// Eventos já processados (ou em processamento) são confirmados sem efeito.
+	claimed, err := h.Events.ClaimStripeEvent(r.Context(), event.ID, event.Type)
+	if err != nil {
+		log.Printf("ERRO: %v", err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	if !claimed {
+		log.Printf("Evento %s (%s) da Stripe já processado, ignorando reenvio.", event.ID, event.Type)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	status := h.handleEvent(event)
+	var procErr error
+	if status >= http.StatusInternalServerError {
+		procErr = fmt.Errorf("processamento do evento %s retornou status %d", event.Type, status)
+	}
+	// Usa um contexto próprio: o registro precisa ser gravado mesmo se a Stripe desconectar.
+	if err := h.Events.FinishStripeEvent(context.Background(), event.ID, procErr); err != nil {
+		log.Printf("AVISO: %v", err)
+	}
+	w.WriteHeader(status)
+}
+
+// provision agenda a criação da conta do pagamento confirmado. Se o agendamento
+// falhar, ele é refeito pela fila de novas tentativas e o evento é confirmado,
+// evitando reenvios da Stripe.
+func (h *WebhookHandler) provision(userID, domain, contactEmail, productID string) int {
+	err := h.Provisioner.ProvisionAccount(userID, domain, contactEmail, productID)
+	if err == nil {
+		return http.StatusOK
+	}
+
+	log.Printf("ERRO CRÍTICO: O pagamento foi recebido, mas o provisionamento FALHOU para o usuário %s: %v", userID, err)
+	if h.Retries == nil {
+		return http.StatusInternalServerError
+	}
+	if err := h.Retries.EnqueueProvisioning(userID, domain, contactEmail, productID, err); err != nil {
+		log.Printf("ERRO: Falha ao reagendar o provisionamento do usuário %s: %v", userID, err)
+		return http.StatusInternalServerError
+	}
+	log.Printf("Provisionamento do usuário %s reagendado para nova tentativa.", userID)
+	return http.StatusOK
+}
+
+// handleEvent despacha o evento para o tratamento adequado e retorna o status HTTP da resposta.
+// Eventos não tratados são confirmados com 200 para que a Stripe não os reenvie.
+func (h *WebhookHandler) handleEvent(event stripe.Event) int {
+	switch event.Type {
+	case "checkout.session.completed",
+		"checkout.session.async_payment_succeeded",
+		"checkout.session.async_payment_failed":
+		return h.handleCheckoutSession(event)
+	case "checkout.session.expired":
+		return h.handleCheckoutExpired(event)
+	case "invoice.paid", "invoice.payment_failed":
+		return h.handleInvoice(event)
+	case "customer.subscription.deleted":
+		return h.handleSubscriptionDeleted(event)
+	case "customer.subscription.updated":
+		return h.handleSubscriptionUpdated(event)
+	case "payment_intent.succeeded", "payment_intent.payment_failed", "payment_intent.canceled":
+		return h.handlePixPayment(event)
+	case "charge.refunded":
+		return h.handleChargeRefunded(event)
+	case "charge.dispute.created":
+		return h.handleDisputeCreated(event)
+	}
+	return http.StatusOK
+}
+
+// handleCheckoutSession provisiona a conta quando o pagamento do checkout é confirmado.
+// Pagamentos por boleto concluem o checkout antes da compensação: nesse caso o
+// cliente recebe o boleto e a conta só é criada no async_payment_succeeded.
+func (h *WebhookHandler) handleCheckoutSession(event stripe.Event) int {
+	var session stripe.CheckoutSession
+	if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
+		log.Printf("ERRO: Falha ao decodificar a sessão do evento da Stripe: %v", err)
+		return http.StatusBadRequest
+	}
+
+	metadata, err := h.checkoutMetadata(&session)
+	if err != nil {
+		log.Printf("ERRO: %v", err)
+		// Falha temporária na API da Stripe: o reenvio do evento tenta novamente.
+		return http.StatusInternalServerError
+	}
+
+	userID := metadata["user_id"]
+	if userID == "" {
+		log.Printf("ERRO: 'user_id' não encontrado nos metadados da sessão %s.", session.ID)
+		return http.StatusOK // Responde OK para não ser reenviado
+	}
+
+	domain := metadata["domain"]
+	productID := metadata["product_id"]
+	contactEmail := checkoutEmail(&session)
+
+	if event.Type == "checkout.session.async_payment_failed" {
+		log.Printf("PAGAMENTO ASSÍNCRONO FALHOU para o usuário: %s (sessão %s)", userID, session.ID)
+		if h.Payments != nil {
+			if err := h.Payments.PaymentFailed(userID); err != nil {
+				log.Printf("ERRO: Falha ao tratar o pagamento não realizado do usuário %s: %v", userID, err)
+			}
 		}
+		return http.StatusOK
+	}
+
+	if session.PaymentStatus == stripe.CheckoutSessionPaymentStatusUnpaid {
+		log.Printf("PAGAMENTO PENDENTE (boleto) para o usuário: %s, domínio: %s", userID, domain)
+		h.notifyPendingPayment(userID, &session)
+		return http.StatusOK
+	}
 
-		// A partir da versão v72 da biblioteca stripe-go, o PaymentIntent está dentro da sessão
-		// e precisamos expandi-lo na chamada de criação da sessão. Se não estiver lá,
-		// pode ser necessário buscar o PaymentIntent separadamente.
-		// Por enquanto, vamos assumir que ele não está diretamente disponível e buscar pelos metadados.
-		metadata := session.Metadata
-		if metadata == nil {
-			// Fallback para o PaymentIntent se os metadados da sessão não estiverem definidos
-			// (isso depende de como a sessão de checkout é criada)
-			log.Printf("AVISO: Metadados não encontrados diretamente na sessão. Verificando PaymentIntent...")
-			// Esta parte pode precisar de mais lógica para buscar o PaymentIntent se não for expandido.
-			// Por simplicidade, vamos parar aqui se não encontrarmos os metadados na sessão.
-			w.WriteHeader(http.StatusBadRequest)
-			return
+	if session.Customer != nil && h.Customers != nil {
+		if err := h.Customers.LinkStripeCustomer(userID, session.Customer.ID, contactEmail); err != nil {
+			// Não impede o provisionamento; o vínculo pode ser refeito no próximo pagamento.
+			log.Printf("AVISO: Falha ao vincular o cliente Stripe %s ao usuário %s: %v", session.Customer.ID, userID, err)
 		}
+	}
 
-		userID, ok := metadata["user_id"]
-		if !ok {
-			log.Printf("ERRO: 'user_id' não encontrado nos metadados da sessão.")
-			w.WriteHeader(http.StatusOK) // Responde OK para não ser reenviado
-			return
+	if session.Subscription != nil && h.Subscriptions != nil {
+		if err := h.Subscriptions.LinkSubscription(userID, session.Subscription.ID); err != nil {
+			log.Printf("AVISO: Falha ao vincular a assinatura %s ao usuário %s: %v", session.Subscription.ID, userID, err)
 		}
+	}
+
+	log.Printf("PAGAMENTO BEM-SUCEDIDO recebido para o usuário: %s, domínio: %s", userID, domain)
+
+	return h.provision(userID, domain, contactEmail, productID)
+}
 
-		domain, _ := metadata["domain"]
-		contactEmail := session.CustomerEmail
+// handleCheckoutExpired avisa o cliente quando o link de checkout expira sem pagamento.
+func (h *WebhookHandler) handleCheckoutExpired(event stripe.Event) int {
+	var session stripe.CheckoutSession
+	if err := json.Unmarshal(event.Data.Raw, &session); err != nil {
+		log.Printf("ERRO: Falha ao decodificar a sessão do evento da Stripe: %v", err)
+		return http.StatusBadRequest
+	}
+	if h.Payments == nil {
+		return http.StatusOK
+	}
 
-		log.Printf("PAGAMENTO BEM-SUCEDIDO recebido para o usuário: %s, domínio: %s", userID, domain)
+	userID := session.Metadata["user_id"]
+	if userID == "" {
+		log.Printf("AVISO: 'user_id' não encontrado nos metadados da sessão expirada %s.", session.ID)
+		return http.StatusOK
+	}
+
+	log.Printf("CHECKOUT EXPIRADO para o usuário: %s (sessão %s)", userID, session.ID)
+	if err := h.Payments.CheckoutExpired(userID, session.ID); err != nil {
+		log.Printf("ERRO: Falha ao tratar o checkout expirado do usuário %s: %v", userID, err)
+		return http.StatusInternalServerError
+	}
+	return http.StatusOK
+}
 
-		if err := h.Provisioner.ProvisionAccount(userID, domain, contactEmail); err != nil {
-			log.Printf("ERRO CRÍTICO: O pagamento foi recebido, mas o provisionamento FALHOU para o usuário %s: %v", userID, err)
-			w.WriteHeader(http.StatusInternalServerError)
-			return
+// checkoutMetadata retorna os metadados do pedido. Sessões criadas antes de os
+// metadados serem gravados na própria sessão só os têm no PaymentIntent ou na assinatura.
+func (h *WebhookHandler) checkoutMetadata(session *stripe.CheckoutSession) (map[string]string, error) {
+	if session.Metadata["user_id"] != "" {
+		return session.Metadata, nil
+	}
+	if h.Client == nil {
+		return session.Metadata, nil
+	}
+
+	log.Printf("AVISO: Metadados não encontrados na sessão %s. Verificando o pagamento...", session.ID)
+	var fallback map[string]string
+	if session.Subscription != nil {
+		s, err := sub.Get(session.Subscription.ID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao buscar a assinatura %s na Stripe: %w", session.Subscription.ID, err)
+		}
+		fallback = s.Metadata
+	} else if session.PaymentIntent != nil {
+		pi, err := h.Client.checkoutPaymentIntent(session)
+		if err != nil {
+			return nil, err
+		}
+		fallback = pi.Metadata
+	}
+
+	// Os metadados da sessão (ex.: product_id) complementam os do pagamento.
+	metadata := make(map[string]string, len(fallback)+len(session.Metadata))
+	for key, value := range session.Metadata {
+		metadata[key] = value
+	}
+	for key, value := range fallback {
+		metadata[key] = value
+	}
+	return metadata, nil
+}
+
+// checkoutEmail retorna o e-mail do cliente: o informado na criação da sessão ou,
+// na falta dele, o digitado no formulário do checkout.
+func checkoutEmail(session *stripe.CheckoutSession) string {
+	if session.CustomerEmail != "" {
+		return session.CustomerEmail
+	}
+	if session.CustomerDetails != nil {
+		return session.CustomerDetails.Email
+	}
+	return ""
+}
+
+// notifyPendingPayment envia ao cliente os dados do boleto gerado no checkout.
+func (h *WebhookHandler) notifyPendingPayment(userID string, session *stripe.CheckoutSession) {
+	if h.Payments == nil || h.Client == nil {
+		return
+	}
+
+	boleto, err := h.Client.boletoDetails(session)
+	if err != nil {
+		log.Printf("AVISO: Não foi possível obter o boleto da sessão %s: %v", session.ID, err)
+		boleto = nil
+	}
+	if err := h.Payments.PaymentPending(userID, boleto); err != nil {
+		log.Printf("AVISO: Falha ao enviar o boleto para o usuário %s: %v", userID, err)
+	}
+}
+
+// handlePixPayment trata a confirmação ou a expiração das cobranças Pix criadas
+// diretamente pelo assistente. Pagamentos feitos pelo Checkout são tratados nos
+// eventos checkout.session.*.
+func (h *WebhookHandler) handlePixPayment(event stripe.Event) int {
+	var pi stripe.PaymentIntent
+	if err := json.Unmarshal(event.Data.Raw, &pi); err != nil {
+		log.Printf("ERRO: Falha ao decodificar o PaymentIntent do evento da Stripe: %v", err)
+		return http.StatusBadRequest
+	}
+	if pi.Metadata["channel"] != paymentChannelPix {
+		return http.StatusOK
+	}
+
+	userID := pi.Metadata["user_id"]
+	if userID == "" {
+		log.Printf("ERRO: 'user_id' não encontrado nos metadados do PaymentIntent %s.", pi.ID)
+		return http.StatusOK // Responde OK para não ser reenviado
+	}
+
+	if event.Type != "payment_intent.succeeded" {
+		// O Pix expirado volta para requires_payment_method (payment_failed) ou é cancelado.
+		log.Printf("PIX NÃO PAGO para o usuário: %s (PaymentIntent %s, evento %s)", userID, pi.ID, event.Type)
+		if h.Payments != nil {
+			if err := h.Payments.PaymentFailed(userID); err != nil {
+				log.Printf("ERRO: Falha ao tratar o Pix não pago do usuário %s: %v", userID, err)
+			}
 		}
+		return http.StatusOK
+	}
+
+	domain := pi.Metadata["domain"]
+	log.Printf("PAGAMENTO PIX BEM-SUCEDIDO recebido para o usuário: %s, domínio: %s", userID, domain)
+
+	if pi.Customer != nil && h.Customers != nil {
+		if err := h.Customers.LinkStripeCustomer(userID, pi.Customer.ID, pi.ReceiptEmail); err != nil {
+			log.Printf("AVISO: Falha ao vincular o cliente Stripe %s ao usuário %s: %v", pi.Customer.ID, userID, err)
+		}
+	}
+
+	return h.provision(userID, domain, pi.ReceiptEmail, pi.Metadata["product_id"])
+}
+
+// handleInvoice trata o pagamento ou a falha de pagamento das faturas de uma assinatura.
+func (h *WebhookHandler) handleInvoice(event stripe.Event) int {
+	var invoice stripe.Invoice
+	if err := json.Unmarshal(event.Data.Raw, &invoice); err != nil {
+		log.Printf("ERRO: Falha ao decodificar a fatura do evento da Stripe: %v", err)
+		return http.StatusBadRequest
+	}
+	if invoice.Subscription == nil || h.Subscriptions == nil {
+		return http.StatusOK
+	}
+	subscriptionID := invoice.Subscription.ID
+	// A primeira fatura é tratada pelo checkout: o pagamento pelo checkout.session.completed,
+	// que provisiona a conta, e a falha pela própria página de checkout. O evento pode
+	// chegar antes de a assinatura ser vinculada ao cliente.
+	if invoice.BillingReason == stripe.InvoiceBillingReasonSubscriptionCreate {
+		return http.StatusOK
+	}
+
+	var err error
+	if event.Type == "invoice.paid" {
+		log.Printf("RENOVAÇÃO PAGA recebida para a assinatura %s (fatura %s)", subscriptionID, invoice.ID)
+		err = h.Subscriptions.SubscriptionPaid(subscriptionID)
+	} else {
+		// Sem próxima tentativa, a Stripe esgotou as retentativas de cobrança.
+		finalAttempt := invoice.NextPaymentAttempt == 0
+		log.Printf("FALHA DE PAGAMENTO na assinatura %s (fatura %s, tentativa %d)", subscriptionID, invoice.ID, invoice.AttemptCount)
+		err = h.Subscriptions.SubscriptionPaymentFailed(subscriptionID, finalAttempt)
+	}
+	return subscriptionResult(event.Type, subscriptionID, err)
+}
+
+// subscriptionResult converte o resultado de um evento de assinatura na resposta à
+// Stripe. Eventos de assinaturas sem cliente vinculado são confirmados: reenviá-los
+// por dias não mudaria nada.
+func subscriptionResult(eventType, subscriptionID string, err error) int {
+	switch {
+	case err == nil:
+		return http.StatusOK
+	case errors.Is(err, ErrSubscriptionNotFound):
+		log.Printf("AVISO: %s ignorado: a assinatura %s não está vinculada a nenhum cliente", eventType, subscriptionID)
+		return http.StatusOK
+	default:
+		log.Printf("ERRO: Falha ao processar %s da assinatura %s: %v", eventType, subscriptionID, err)
+		return http.StatusInternalServerError
+	}
+}
+
+// handleSubscriptionDeleted encerra a conta quando a assinatura é cancelada.
+func (h *WebhookHandler) handleSubscriptionDeleted(event stripe.Event) int {
+	var subscription stripe.Subscription
+	if err := json.Unmarshal(event.Data.Raw, &subscription); err != nil {
+		log.Printf("ERRO: Falha ao decodificar a assinatura do evento da Stripe: %v", err)
+		return http.StatusBadRequest
+	}
+	if h.Subscriptions == nil {
+		return http.StatusOK
+	}
+
+	log.Printf("ASSINATURA CANCELADA: %s", subscription.ID)
+	return subscriptionResult(event.Type, subscription.ID, h.Subscriptions.SubscriptionCanceled(subscription.ID))
+}
+
+// handleSubscriptionUpdated troca o pacote da conta quando o cliente muda de plano.
+func (h *WebhookHandler) handleSubscriptionUpdated(event stripe.Event) int {
+	// Só interessa a troca de itens (plano); mudanças de status chegam em outros eventos.
+	if _, changed := event.Data.PreviousAttributes["items"]; !changed {
+		return http.StatusOK
+	}
+
+	var subscription stripe.Subscription
+	if err := json.Unmarshal(event.Data.Raw, &subscription); err != nil {
+		log.Printf("ERRO: Falha ao decodificar a assinatura do evento da Stripe: %v", err)
+		return http.StatusBadRequest
+	}
+	if h.Subscriptions == nil || subscription.Items == nil || len(subscription.Items.Data) == 0 {
+		return http.StatusOK
+	}
+	item := subscription.Items.Data[0]
+	if item.Price == nil || item.Price.Product == nil {
+		return http.StatusOK
 	}
 
-	w.WriteHeader(http.StatusOK)
+	log.Printf("TROCA DE PLANO na assinatura %s: produto %s", subscription.ID, item.Price.Product.ID)
+	err := h.Subscriptions.SubscriptionPlanChanged(subscription.ID, item.Price.Product.ID)
+	return subscriptionResult(event.Type, subscription.ID, err)
 }
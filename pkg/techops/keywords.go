@@ -0,0 +1,86 @@
+package techops
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// categoryKeywords relaciona termos frequentes a cada categoria, sem acentos e em
+// minúsculas. A primeira categoria com um termo presente é a escolhida.
+var categoryKeywords = []struct {
+	category Category
+	terms    []string
+}{
+	{Legal, []string{"contrato", "processo", "juridico", "advogado", "notificacao", "anpd", "multa"}},
+	{LGPD, []string{"lgpd", "dados pessoais", "privacidade", "consentimento", "titular", "dpo"}},
+	{Security, []string{"invadido", "invasao", "hacker", "ataque", "vazamento", "malware", "virus", "seguranca", "senha", "phishing", "ransomware"}},
+	{Performance, []string{"lento", "lentidao", "performance", "desempenho", "travando", "demora", "timeout"}},
+	{Architecture, []string{"arquitetura", "escalar", "escalabilidade", "migracao", "nuvem", "cloud", "microsservico", "banco de dados"}},
+}
+
+// urgentTerms indicam incidente em andamento. Palavras genéricas de pressa, como
+// "urgente" ou "agora", ficam de fora: aparecem em pedidos comuns de orçamento.
+var urgentTerms = []string{"invadido", "invasao", "vazamento", "ransomware", "fora do ar", "sequestro"}
+
+// ClassifyKeywords classifica o lead por palavras-chave. É determinístico e serve de
+// alternativa quando o classificador por IA está indisponível ou responde fora do esquema.
+func ClassifyKeywords(lead Lead) Assessment {
+	text := " " + fold(lead.ProblemDescription) + " "
+
+	assessment := Assessment{
+		Category:   Architecture,
+		Urgency:    LevelMedium,
+		Complexity: LevelMedium,
+		Summary:    summarize(lead.ProblemDescription),
+	}
+	for _, group := range categoryKeywords {
+		if containsAny(text, group.terms) {
+			assessment.Category = group.category
+			break
+		}
+	}
+	if containsAny(text, urgentTerms) {
+		assessment.Urgency = LevelCritical
+	}
+	return assessment
+}
+
+// summarize usa o próprio relato do cliente como resumo, limitado ao tamanho aceito.
+func summarize(description string) string {
+	summary := strings.Join(strings.Fields(description), " ")
+	if summary == "" {
+		return "Cliente não descreveu o problema."
+	}
+	runes := []rune(summary)
+	if len(runes) > maxSummaryLength {
+		summary = string(runes[:maxSummaryLength-1]) + "…"
+	}
+	return summary
+}
+
+func containsAny(text string, terms []string) bool {
+	for _, term := range terms {
+		if strings.Contains(text, " "+term+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// fold remove acentos e pontuação e deixa o texto em minúsculas, com espaços simples.
+func fold(text string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(strings.ToLower(text)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Marca de acento separada pela decomposição NFD.
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		default:
+			b.WriteRune(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
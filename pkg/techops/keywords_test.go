@@ -0,0 +1,57 @@
+package techops
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassifyKeywords(t *testing.T) {
+	tests := []struct {
+		name         string
+		description  string
+		wantCategory Category
+		wantUrgency  string
+	}{
+		{name: "incidente de segurança", description: "Nosso site foi INVADIDO ontem à noite!", wantCategory: Security, wantUrgency: LevelCritical},
+		{name: "acentos ignorados", description: "Precisamos nos adequar à LGPD e revisar a privacidade.", wantCategory: LGPD, wantUrgency: LevelMedium},
+		{name: "jurídico antes de LGPD", description: "Recebemos uma notificação da ANPD sobre a LGPD.", wantCategory: Legal, wantUrgency: LevelMedium},
+		{name: "lentidão", description: "O sistema está lento e dá timeout.", wantCategory: Performance, wantUrgency: LevelMedium},
+		{name: "fora do ar é urgente", description: "A loja está fora do ar.", wantCategory: Architecture, wantUrgency: LevelCritical},
+		{name: "pressa não é incidente", description: "Preciso de uma proposta de migração para a nuvem agora, é urgente.", wantCategory: Architecture, wantUrgency: LevelMedium},
+		{name: "termo dentro de outra palavra", description: "Quero contratar um serviço de consultoria.", wantCategory: Architecture, wantUrgency: LevelMedium},
+		{name: "sem termos conhecidos", description: "Quero conversar.", wantCategory: Architecture, wantUrgency: LevelMedium},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyKeywords(Lead{ProblemDescription: tt.description})
+			if got.Category != tt.wantCategory {
+				t.Errorf("categoria = %q, esperava %q", got.Category, tt.wantCategory)
+			}
+			if got.Urgency != tt.wantUrgency {
+				t.Errorf("urgência = %q, esperava %q", got.Urgency, tt.wantUrgency)
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("classificação inválida: %v", err)
+			}
+		})
+	}
+}
+
+func TestClassifyKeywordsSummary(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{name: "espaços normalizados", description: "  site   lento\n\nhoje ", want: "site lento hoje"},
+		{name: "relato vazio", description: " ", want: "Cliente não descreveu o problema."},
+		{name: "relato longo", description: strings.Repeat("é", maxSummaryLength+10), want: strings.Repeat("é", maxSummaryLength-1) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyKeywords(Lead{ProblemDescription: tt.description}).Summary; got != tt.want {
+				t.Errorf("resumo = %q, esperava %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+// Package techops classifica os leads do Tech Ops a partir da pré-análise coletada na conversa.
+package techops
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Category é a área de especialidade do caso.
+type Category string
+
+const (
+	Security     Category = "security"
+	Architecture Category = "architecture"
+	LGPD         Category = "lgpd"
+	Performance  Category = "performance"
+	Legal        Category = "legal"
+)
+
+// Categories lista as categorias aceitas.
+var Categories = []Category{Security, Architecture, LGPD, Performance, Legal}
+
+// Níveis de urgência e de complexidade aceitos.
+const (
+	LevelLow      = "low"
+	LevelMedium   = "medium"
+	LevelHigh     = "high"
+	LevelCritical = "critical" // Apenas para urgência: incidente em andamento
+)
+
+// Urgencies e Complexities listam os níveis aceitos em cada campo.
+var (
+	Urgencies    = []string{LevelLow, LevelMedium, LevelHigh, LevelCritical}
+	Complexities = []string{LevelLow, LevelMedium, LevelHigh}
+)
+
+// maxSummaryLength limita o resumo gravado na sessão e enviado à equipe.
+const maxSummaryLength = 500
+
+// Lead reúne os dados da pré-análise.
+type Lead struct {
+	ProblemDescription string
+	RepoURL            string
+	SystemURL          string
+}
+
+// Assessment é a classificação de um lead.
+type Assessment struct {
+	Category   Category
+	Urgency    string
+	Complexity string
+	Summary    string
+}
+
+// Validate verifica se todos os campos estão dentro dos valores aceitos.
+func (a Assessment) Validate() error {
+	if !contains(CategoryNames(), string(a.Category)) {
+		return fmt.Errorf("categoria inválida: %q", a.Category)
+	}
+	if !contains(Urgencies, a.Urgency) {
+		return fmt.Errorf("urgência inválida: %q", a.Urgency)
+	}
+	if !contains(Complexities, a.Complexity) {
+		return fmt.Errorf("complexidade inválida: %q", a.Complexity)
+	}
+	if strings.TrimSpace(a.Summary) == "" {
+		return fmt.Errorf("resumo vazio")
+	}
+	if len([]rune(a.Summary)) > maxSummaryLength {
+		return fmt.Errorf("resumo com mais de %d caracteres", maxSummaryLength)
+	}
+	return nil
+}
+
+// Classifier classifica um lead do Tech Ops.
+type Classifier interface {
+	ClassifyLead(ctx context.Context, lead Lead) (Assessment, error)
+}
+
+// CategoryNames retorna as categorias aceitas como texto, para validação e esquemas de resposta.
+func CategoryNames() []string {
+	names := make([]string, len(Categories))
+	for i, c := range Categories {
+		names[i] = string(c)
+	}
+	return names
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,34 @@
+package techops
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAssessmentValidate(t *testing.T) {
+	valid := Assessment{Category: Security, Urgency: LevelCritical, Complexity: LevelHigh, Summary: "Site invadido."}
+
+	tests := []struct {
+		name    string
+		modify  func(a *Assessment)
+		wantErr bool
+	}{
+		{name: "válida", modify: func(a *Assessment) {}},
+		{name: "categoria desconhecida", modify: func(a *Assessment) { a.Category = "marketing" }, wantErr: true},
+		{name: "urgência desconhecida", modify: func(a *Assessment) { a.Urgency = "urgent" }, wantErr: true},
+		{name: "complexidade crítica", modify: func(a *Assessment) { a.Complexity = LevelCritical }, wantErr: true},
+		{name: "resumo vazio", modify: func(a *Assessment) { a.Summary = "  " }, wantErr: true},
+		{name: "resumo no limite", modify: func(a *Assessment) { a.Summary = strings.Repeat("ã", maxSummaryLength) }},
+		{name: "resumo acima do limite", modify: func(a *Assessment) { a.Summary = strings.Repeat("a", maxSummaryLength+1) }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := valid
+			tt.modify(&a)
+			err := a.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() = %v, esperava erro: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -52,9 +52,12 @@ func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		responseText = "Desculpe, ocorreu um erro ao processar sua solicitação. Tente novamente."
 	}
 
-	// Envia a resposta de volta ao usuário.
-	if err := h.WhatsAppClient.SendMessage(senderPhone, responseText); err != nil {
-		log.Printf("Erro ao enviar resposta do WhatsApp: %v", err)
+	// Envia a resposta de volta ao usuário. Uma resposta vazia indica que o
+	// StateManager já enviou a mensagem (ex.: mensagens interativas).
+	if responseText != "" {
+		if err := h.WhatsAppClient.SendMessage(senderPhone, responseText); err != nil {
+			log.Printf("Erro ao enviar resposta do WhatsApp: %v", err)
+		}
 	}
 
 	w.WriteHeader(http.StatusOK)
@@ -70,6 +73,16 @@ func extractMessageText(req map[string]interface{}) string {
 					if text, ok := messages[0].(map[string]interface{})["text"].(map[string]interface{}); ok {
 						return text["body"].(string)
 					}
+					// Respostas de mensagens interativas (listas e botões) chegam com o ID da opção.
+					if interactive, ok := messages[0].(map[string]interface{})["interactive"].(map[string]interface{}); ok {
+						for _, key := range []string{"list_reply", "button_reply"} {
+							if reply, ok := interactive[key].(map[string]interface{}); ok {
+								if id, ok := reply["id"].(string); ok {
+									return id
+								}
+							}
+						}
+					}
 				}
 			}
 		}
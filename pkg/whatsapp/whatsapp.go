@@ -2,40 +2,94 @@ package whatsapp
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 )
 
 // Client é um cliente para interagir com a API do WhatsApp
 type Client struct {
-	Token      string
-	BusinessID string
+	Token         string
+	BusinessID    string
 	PhoneNumberID string
-	HTTPClient *http.Client
+	HTTPClient    *http.Client
 }
 
 // NewClient cria um novo cliente do WhatsApp
 func NewClient(token, businessID, phoneNumberID string) *Client {
 	return &Client{
-		Token:      token,
-		BusinessID: businessID,
+		Token:         token,
+		BusinessID:    businessID,
 		PhoneNumberID: phoneNumberID,
-		HTTPClient: &http.Client{},
+		HTTPClient:    &http.Client{},
 	}
 }
 
+// ListRow é uma opção de uma mensagem de lista.
+type ListRow struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`                 // Máximo de 24 caracteres
+	Description string `json:"description,omitempty"` // Máximo de 72 caracteres
+}
+
+// ListSection agrupa opções de uma mensagem de lista.
+type ListSection struct {
+	Title string    `json:"title,omitempty"`
+	Rows  []ListRow `json:"rows"`
+}
+
 // SendMessage envia uma mensagem de texto para um destinatário
 func (c *Client) SendMessage(to, message string) error {
+	return c.send(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                to,
+		"type":              "text",
+		"text":              map[string]string{"body": message},
+	})
+}
+
+// SendListMessage envia uma mensagem interativa de lista. A opção escolhida pelo
+// usuário chega no webhook com o ID da linha selecionada.
+func (c *Client) SendListMessage(to, body, buttonText string, sections []ListSection) error {
+	return c.send(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                to,
+		"type":              "interactive",
+		"interactive": map[string]interface{}{
+			"type": "list",
+			"body": map[string]string{"text": body},
+			"action": map[string]interface{}{
+				"button":   buttonText,
+				"sections": sections,
+			},
+		},
+	})
+}
+
+// SendImage envia uma imagem a partir de uma URL pública, com legenda opcional.
+func (c *Client) SendImage(to, imageURL, caption string) error {
+	image := map[string]string{"link": imageURL}
+	if caption != "" {
+		image["caption"] = caption
+	}
+	return c.send(map[string]interface{}{
+		"messaging_product": "whatsapp",
+		"to":                to,
+		"type":              "image",
+		"image":             image,
+	})
+}
+
+// send codifica o payload e o envia para a API de mensagens.
+func (c *Client) send(payload interface{}) error {
 	url := fmt.Sprintf("https://graph.facebook.com/v22.0/%s/messages", c.PhoneNumberID)
 
-	payload := []byte(fmt.Sprintf(`{ 
-		"messaging_product": "whatsapp", 
-		"to": "%s", 
-		"type": "text", 
-		"text": { "body": "%s" } 
-	}`, to, message))
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
 	if err != nil {
 		return err
 	}
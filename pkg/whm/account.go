@@ -0,0 +1,216 @@
+package whm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// SuspendAccount chama a API 'suspendacct' do WHM para suspender uma conta.
+func (c *Client) SuspendAccount(username, reason string) error {
+	query := url.Values{}
+	query.Set("user", username)
+	query.Set("reason", reason)
+	return c.exec("suspendacct", query)
+}
+
+// UnsuspendAccount chama a API 'unsuspendacct' do WHM para reativar uma conta suspensa.
+func (c *Client) UnsuspendAccount(username string) error {
+	query := url.Values{}
+	query.Set("user", username)
+	return c.exec("unsuspendacct", query)
+}
+
+// TerminateAccount chama a API 'removeacct' do WHM para remover uma conta definitivamente.
+func (c *Client) TerminateAccount(username string) error {
+	query := url.Values{}
+	query.Set("username", username)
+	return c.exec("removeacct", query)
+}
+
+// ChangePackage chama a API 'changepackage' do WHM para trocar o plano de uma conta.
+func (c *Client) ChangePackage(username, pkg string) error {
+	query := url.Values{}
+	query.Set("user", username)
+	query.Set("pkg", pkg)
+	return c.exec("changepackage", query)
+}
+
+// ModifyAccountParams define os dados a alterar em uma conta. Campos vazios não são alterados.
+type ModifyAccountParams struct {
+	NewUsername  string
+	Domain       string
+	ContactEmail string
+}
+
+// ModifyAccount chama a API 'modifyacct' do WHM para alterar os dados de uma conta.
+func (c *Client) ModifyAccount(username string, params ModifyAccountParams) error {
+	query := url.Values{}
+	query.Set("user", username)
+	if params.NewUsername != "" {
+		query.Set("newuser", params.NewUsername)
+	}
+	if params.Domain != "" {
+		query.Set("DNS", params.Domain)
+	}
+	if params.ContactEmail != "" {
+		query.Set("contactemail", params.ContactEmail)
+	}
+	if len(query) == 1 {
+		return fmt.Errorf("nenhuma alteração informada para a conta %s", username)
+	}
+	return c.exec("modifyacct", query)
+}
+
+// Package representa um pacote (plano) do WHM retornado por 'listpkgs'.
+// Os limites vêm como número ou "unlimited".
+type Package struct {
+	Name             string
+	DiskQuotaMB      string
+	BandwidthMB      string
+	MaxEmailAccounts string
+	MaxDatabases     string
+	MaxAddonDomains  string
+	MaxSubdomains    string
+	FeatureList      string
+}
+
+// Unlimited indica se o limite do pacote é ilimitado.
+func Unlimited(limit string) bool {
+	return limit == "" || limit == "unlimited"
+}
+
+// apiValue aceita os valores do WHM que podem vir como texto ou número.
+type apiValue string
+
+func (v *apiValue) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err == nil {
+		*v = apiValue(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+	*v = apiValue(n.String())
+	return nil
+}
+
+// listPackagesData reflete os dados de 'listpkgs'.
+type listPackagesData struct {
+	Pkg []struct {
+		Name        string   `json:"name"`
+		Quota       apiValue `json:"QUOTA"`
+		BWLimit     apiValue `json:"BWLIMIT"`
+		MaxPop      apiValue `json:"MAXPOP"`
+		MaxSQL      apiValue `json:"MAXSQL"`
+		MaxAddon    apiValue `json:"MAXADDON"`
+		MaxSub      apiValue `json:"MAXSUB"`
+		FeatureList apiValue `json:"FEATURELIST"`
+	} `json:"pkg"`
+}
+
+// ListPackages chama a API 'listpkgs' do WHM e retorna os pacotes disponíveis.
+func (c *Client) ListPackages() ([]Package, error) {
+	data, err := request[listPackagesData](c, "listpkgs", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]Package, 0, len(data.Pkg))
+	for _, p := range data.Pkg {
+		packages = append(packages, Package{
+			Name:             p.Name,
+			DiskQuotaMB:      string(p.Quota),
+			BandwidthMB:      string(p.BWLimit),
+			MaxEmailAccounts: string(p.MaxPop),
+			MaxDatabases:     string(p.MaxSQL),
+			MaxAddonDomains:  string(p.MaxAddon),
+			MaxSubdomains:    string(p.MaxSub),
+			FeatureList:      string(p.FeatureList),
+		})
+	}
+	return packages, nil
+}
+
+// FindPackage retorna o pacote do WHM com o nome informado.
+func (c *Client) FindPackage(name string) (*Package, error) {
+	packages, err := c.ListPackages()
+	if err != nil {
+		return nil, err
+	}
+	for i := range packages {
+		if packages[i].Name == name {
+			return &packages[i], nil
+		}
+	}
+	return nil, fmt.Errorf("pacote %s não encontrado no WHM", name)
+}
+
+// LimitValue converte um limite do pacote em número; ilimitado retorna 0 e false.
+func LimitValue(limit string) (int, bool) {
+	if Unlimited(limit) {
+		return 0, false
+	}
+	n, err := strconv.Atoi(limit)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// AccountExists chama a API 'accountsummary' do WHM para saber se a conta existe.
+func (c *Client) AccountExists(username string) (bool, error) {
+	domain, err := c.AccountDomain(username)
+	return domain != "", err
+}
+
+// AccountDomain chama a API 'accountsummary' do WHM e retorna o domínio principal
+// da conta, ou "" se a conta não existir.
+func (c *Client) AccountDomain(username string) (string, error) {
+	query := url.Values{}
+	query.Set("user", username)
+
+	env, err := c.call("accountsummary", query)
+	if err != nil {
+		return "", err
+	}
+	if env.Metadata.Result == 1 {
+		var data struct {
+			Acct []struct {
+				Domain string `json:"domain"`
+			} `json:"acct"`
+		}
+		if err := json.Unmarshal(env.Data, &data); err != nil {
+			return "", fmt.Errorf("falha ao decodificar os dados de accountsummary do WHM: %w", err)
+		}
+		if len(data.Acct) == 0 || data.Acct[0].Domain == "" {
+			return "", fmt.Errorf("o WHM não informou o domínio da conta %s", username)
+		}
+		return data.Acct[0].Domain, nil
+	}
+	// accountsummary também falha por autenticação, permissão ou bloqueio; só o
+	// motivo de conta inexistente significa que ela não existe.
+	if missingAccount(env.Metadata.Reason) {
+		return "", nil
+	}
+	return "", &APIError{Function: "accountsummary", Reason: env.Metadata.Reason}
+}
+
+// missingAccount indica se o motivo do erro do WHM é uma conta inexistente
+// (ex.: "Account does not exist." ou "No such user").
+func missingAccount(reason string) bool {
+	reason = strings.ToLower(reason)
+	return strings.Contains(reason, "does not exist") || strings.Contains(reason, "no such user")
+}
+
+// ChangePassword chama a API 'passwd' do WHM para definir uma nova senha para a conta.
+func (c *Client) ChangePassword(username, password string) error {
+	query := url.Values{}
+	query.Set("user", username)
+	query.Set("password", password)
+	return c.exec("passwd", query)
+}
@@ -1,36 +1,102 @@
-
 package whm
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"log"
+	"net"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
+// defaultPort é a porta HTTPS do WHM, usada quando WHM_HOST não informa outra.
+const defaultPort = "2087"
+
+// defaultTimeout é o tempo máximo de uma chamada à API quando não configurado.
+const defaultTimeout = 30 * time.Second
+
 // Client é um cliente para a API do WHM.
 type Client struct {
-	Host       string
+	// BaseURL é o endereço normalizado do WHM (ex.: https://servidor:2087).
+	BaseURL    string
 	APIToken   string
 	HTTPClient *http.Client
 }
 
-// NewClient cria um novo cliente WHM.
-func NewClient(host, apiToken string) *Client {
+// Options define as configurações de conexão com o WHM.
+type Options struct {
+	Timeout time.Duration
+	// CABundle é o caminho de um arquivo PEM com as autoridades certificadoras
+	// aceitas, para servidores com certificado próprio. Vazio usa as do sistema.
+	CABundle string
+}
+
+// NewClient cria um novo cliente WHM. host pode vir com ou sem esquema e porta
+// (ex.: "servidor.com", "https://servidor.com" ou "servidor.com:2087").
+func NewClient(host, apiToken string, opts Options) (*Client, error) {
+	baseURL, err := normalizeHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.CABundle != "" {
+		pem, err := ioutil.ReadFile(opts.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao ler o CA bundle do WHM: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("nenhum certificado válido no CA bundle do WHM %s", opts.CABundle)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+
 	return &Client{
-		Host:       host,
+		BaseURL:    baseURL,
 		APIToken:   apiToken,
-		HTTPClient: &http.Client{},
+		HTTPClient: &http.Client{Timeout: timeout, Transport: transport},
+	}, nil
+}
+
+// normalizeHost monta a URL base do WHM, sempre em HTTPS e com a porta 2087 por padrão.
+func normalizeHost(host string) (string, error) {
+	host = strings.TrimSpace(host)
+	if host == "" {
+		return "", fmt.Errorf("host do WHM não informado")
 	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+
+	u, err := url.Parse(host)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("host do WHM inválido: %q", host)
+	}
+	if u.Scheme != "https" {
+		return "", fmt.Errorf("o WHM deve ser acessado via https, recebido %q", u.Scheme)
+	}
+	if u.Port() == "" {
+		u.Host = net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+	return fmt.Sprintf("https://%s", u.Host), nil
 }
 
 // CreateAccountParams define os parâmetros para a criação de uma conta no WHM.
 type CreateAccountParams struct {
-	Username string
-	Domain   string
-	Plan     string
-	Password string
+	Username     string
+	Domain       string
+	Plan         string
+	Password     string
 	ContactEmail string
 }
 
@@ -39,6 +105,47 @@ type WHMResponseMetadata struct {
 	Command string `json:"command"`
 	Reason  string `json:"reason"`
 	Result  int    `json:"result"`
+	Version int    `json:"version"`
+	Output  struct {
+		Warnings messageList `json:"warnings"`
+		Messages messageList `json:"messages"`
+	} `json:"output"`
+}
+
+// messageList aceita mensagens do WHM enviadas como texto único ou como lista.
+type messageList []string
+
+func (m *messageList) UnmarshalJSON(b []byte) error {
+	var list []string
+	if err := json.Unmarshal(b, &list); err == nil {
+		*m = list
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(b, &single); err != nil {
+		// Formatos inesperados (ex.: objetos) não impedem a leitura da resposta.
+		return nil
+	}
+	if single != "" {
+		*m = messageList{single}
+	}
+	return nil
+}
+
+// APIError é um erro lógico retornado pela API do WHM (metadata.result = 0).
+type APIError struct {
+	Function string
+	Reason   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("erro da API WHM (%s): %s", e.Function, e.Reason)
+}
+
+// envelope é o formato comum das respostas da API 1 do WHM.
+type envelope struct {
+	Metadata WHMResponseMetadata `json:"metadata"`
+	Data     json.RawMessage     `json:"data"`
 }
 
 // CreateAccountResponse define a estrutura da resposta bem-sucedida de 'createacct'.
@@ -46,52 +153,83 @@ type CreateAccountResponse struct {
 	Metadata WHMResponseMetadata `json:"metadata"`
 }
 
-
 // CreateAccount chama a API 'createacct' do WHM para provisionar uma nova conta.
 func (c *Client) CreateAccount(params CreateAccountParams) (*CreateAccountResponse, error) {
-	// 1. Monta a URL da API com os parâmetros necessários.
-	query := url.Values{}
-	query.Set("api.version", "1")
-	query.Set("username", params.Username)
-	query.Set("domain", params.Domain)
-	query.Set("plan", params.Plan)
-	query.Set("password", params.Password)
-	query.Set("contactemail", params.ContactEmail)
-
-	apiURL := fmt.Sprintf("https://%s:2087/json-api/createacct?%s", c.Host, query.Encode())
-
-	// 2. Cria a requisição HTTP GET.
-	req, err := http.NewRequest("GET", apiURL, nil)
+	form := url.Values{}
+	form.Set("username", params.Username)
+	form.Set("domain", params.Domain)
+	form.Set("plan", params.Plan)
+	form.Set("password", params.Password)
+	form.Set("contactemail", params.ContactEmail)
+
+	env, err := c.call("createacct", form)
 	if err != nil {
-		return nil, fmt.Errorf("falha ao criar requisição para o WHM: %w", err)
+		return nil, err
 	}
+	if env.Metadata.Result == 0 {
+		return nil, &APIError{Function: "createacct", Reason: env.Metadata.Reason}
+	}
+	return &CreateAccountResponse{Metadata: env.Metadata}, nil
+}
+
+// exec executa uma função da API 1 do WHM que não retorna dados, apenas o resultado.
+func (c *Client) exec(function string, form url.Values) error {
+	_, err := request[json.RawMessage](c, function, form)
+	return err
+}
+
+// request executa uma função da API 1 do WHM e decodifica o campo data da resposta em T.
+func request[T any](c *Client, function string, form url.Values) (T, error) {
+	var data T
 
-	// 3. Adiciona o header de autorização.
+	env, err := c.call(function, form)
+	if err != nil {
+		return data, err
+	}
+	if env.Metadata.Result == 0 {
+		return data, &APIError{Function: function, Reason: env.Metadata.Reason}
+	}
+	if len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, &data); err != nil {
+			return data, fmt.Errorf("falha ao decodificar os dados de %s do WHM: %w", function, err)
+		}
+	}
+	return data, nil
+}
+
+// call executa uma função da API 1 do WHM e retorna a resposta, sem tratar o
+// resultado como erro. Os parâmetros vão no corpo do POST, fora dos logs de acesso.
+func (c *Client) call(function string, form url.Values) (*envelope, error) {
+	form.Set("api.version", "1")
+	apiURL := fmt.Sprintf("%s/json-api/%s", c.BaseURL, function)
+
+	req, err := http.NewRequest("POST", apiURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("falha ao criar requisição para o WHM: %w", err)
+	}
 	req.Header.Add("Authorization", fmt.Sprintf("whm %s", c.APIToken))
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	// 4. Executa a requisição.
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("falha ao enviar requisição para o WHM: %w", err)
+		return nil, fmt.Errorf("falha ao enviar requisição para o WHM (%s): %w", function, err)
 	}
 	defer resp.Body.Close()
 
-	// 5. Lê e decodifica a resposta.
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("falha ao ler resposta do WHM: %w", err)
 	}
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("o WHM respondeu %s à chamada %s", resp.Status, function)
+	}
 
-	var whmResponse CreateAccountResponse
-	if err := json.Unmarshal(body, &whmResponse); err != nil {
+	var env envelope
+	if err := json.Unmarshal(body, &env); err != nil {
 		return nil, fmt.Errorf("falha ao decodificar JSON do WHM: %w (resposta: %s)", err, string(body))
 	}
-
-	// 6. Verifica se a API retornou um erro lógico.
-	if whmResponse.Metadata.Result == 0 {
-		return nil, fmt.Errorf("erro da API WHM: %s", whmResponse.Metadata.Reason)
+	for _, warning := range env.Metadata.Output.Warnings {
+		log.Printf("AVISO: WHM %s: %s", function, warning)
 	}
-
-	// 7. Retorna a resposta bem-sucedida.
-	return &whmResponse, nil
+	return &env, nil
 }
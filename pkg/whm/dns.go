@@ -0,0 +1,219 @@
+package whm
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Tipos de registro DNS que os clientes podem gerenciar pelo WhatsApp.
+const (
+	RecordTypeA     = "A"
+	RecordTypeCNAME = "CNAME"
+	RecordTypeMX    = "MX"
+	RecordTypeTXT   = "TXT"
+)
+
+// defaultRecordTTL é o TTL usado nos registros criados ou editados pelo assistente.
+const defaultRecordTTL = 14400
+
+// ZoneRecord representa um registro retornado por 'dumpzone'.
+type ZoneRecord struct {
+	Line       int    `json:"Line"`
+	Type       string `json:"type"`
+	Name       string `json:"name"`
+	TTL        int    `json:"ttl"`
+	Address    string `json:"address"`
+	CName      string `json:"cname"`
+	Exchange   string `json:"exchange"`
+	Preference int    `json:"preference"`
+	TXTData    string `json:"txtdata"`
+}
+
+// Value retorna o conteúdo do registro em formato legível.
+func (r ZoneRecord) Value() string {
+	switch r.Type {
+	case RecordTypeA:
+		return r.Address
+	case RecordTypeCNAME:
+		return r.CName
+	case RecordTypeMX:
+		return fmt.Sprintf("%d %s", r.Preference, r.Exchange)
+	case RecordTypeTXT:
+		return r.TXTData
+	default:
+		return ""
+	}
+}
+
+// ZoneRecordParams define os dados de um registro a ser criado ou editado.
+type ZoneRecordParams struct {
+	// Name é o nome relativo à zona ("@" para a raiz) ou um FQDN terminado em ".".
+	Name  string
+	Type  string
+	Value string
+	// Priority é usada apenas em registros MX.
+	Priority int
+}
+
+// dumpZoneData define a estrutura dos dados de 'dumpzone'.
+type dumpZoneData struct {
+	Zone []struct {
+		Record []ZoneRecord `json:"record"`
+	} `json:"zone"`
+}
+
+// DumpZone chama a API 'dumpzone' do WHM e retorna os registros da zona do domínio.
+func (c *Client) DumpZone(domain string) ([]ZoneRecord, error) {
+	query := url.Values{}
+	query.Set("domain", domain)
+
+	data, err := request[dumpZoneData](c, "dumpzone", query)
+	if err != nil {
+		return nil, err
+	}
+
+	var records []ZoneRecord
+	for _, zone := range data.Zone {
+		records = append(records, zone.Record...)
+	}
+	return records, nil
+}
+
+// AddZoneRecord chama a API 'addzonerecord' do WHM para criar um registro na zona.
+func (c *Client) AddZoneRecord(domain string, params ZoneRecordParams) error {
+	if err := params.Validate(domain); err != nil {
+		return err
+	}
+
+	query := recordQuery(domain, params)
+	return c.exec("addzonerecord", query)
+}
+
+// EditZoneRecord chama a API 'editzonerecord' do WHM para alterar o registro da linha informada.
+func (c *Client) EditZoneRecord(domain string, line int, params ZoneRecordParams) error {
+	if err := params.Validate(domain); err != nil {
+		return err
+	}
+
+	query := recordQuery(domain, params)
+	query.Set("line", strconv.Itoa(line))
+	return c.exec("editzonerecord", query)
+}
+
+// RemoveZoneRecord chama a API 'removezonerecord' do WHM para apagar o registro da linha informada.
+func (c *Client) RemoveZoneRecord(domain string, line int) error {
+	query := url.Values{}
+	query.Set("zone", domain)
+	query.Set("line", strconv.Itoa(line))
+	return c.exec("removezonerecord", query)
+}
+
+func recordQuery(domain string, params ZoneRecordParams) url.Values {
+	query := url.Values{}
+	query.Set("zone", domain)
+	query.Set("name", RecordFQDN(params.Name, domain))
+	query.Set("type", params.Type)
+	query.Set("ttl", strconv.Itoa(defaultRecordTTL))
+
+	switch params.Type {
+	case RecordTypeA:
+		query.Set("address", params.Value)
+	case RecordTypeCNAME:
+		query.Set("cname", strings.TrimSuffix(params.Value, ".")+".")
+	case RecordTypeMX:
+		query.Set("exchange", strings.TrimSuffix(params.Value, "."))
+		query.Set("preference", strconv.Itoa(params.Priority))
+	case RecordTypeTXT:
+		query.Set("txtdata", params.Value)
+	}
+	return query
+}
+
+// RecordFQDN converte o nome de um registro para o FQDN dentro da zona do domínio.
+func RecordFQDN(name, domain string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	switch {
+	case name == "" || name == "@":
+		return domain + "."
+	case strings.HasSuffix(name, "."):
+		return name
+	case name == domain || strings.HasSuffix(name, "."+domain):
+		return name + "."
+	default:
+		return name + "." + domain + "."
+	}
+}
+
+// Validate verifica se o registro é suportado e se o valor é válido para o tipo.
+func (p ZoneRecordParams) Validate(domain string) error {
+	fqdn := strings.TrimSuffix(RecordFQDN(p.Name, domain), ".")
+	if fqdn != domain && !strings.HasSuffix(fqdn, "."+domain) {
+		return fmt.Errorf("o nome %q não pertence à zona %s", p.Name, domain)
+	}
+	if !isValidHostname(strings.TrimPrefix(fqdn, "*."), true) {
+		return fmt.Errorf("nome de registro inválido: %q", p.Name)
+	}
+
+	switch p.Type {
+	case RecordTypeA:
+		ip := net.ParseIP(p.Value)
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("endereço IPv4 inválido: %q", p.Value)
+		}
+	case RecordTypeCNAME:
+		if fqdn == domain {
+			return fmt.Errorf("não é permitido criar CNAME na raiz do domínio")
+		}
+		if !isValidHostname(strings.TrimSuffix(p.Value, "."), false) {
+			return fmt.Errorf("destino de CNAME inválido: %q", p.Value)
+		}
+	case RecordTypeMX:
+		if p.Priority < 0 || p.Priority > 65535 {
+			return fmt.Errorf("prioridade de MX inválida: %d", p.Priority)
+		}
+		if !isValidHostname(strings.TrimSuffix(p.Value, "."), false) {
+			return fmt.Errorf("servidor de e-mail inválido: %q", p.Value)
+		}
+	case RecordTypeTXT:
+		if p.Value == "" || len(p.Value) > 255 {
+			return fmt.Errorf("o conteúdo TXT deve ter entre 1 e 255 caracteres")
+		}
+		for _, r := range p.Value {
+			if r < 0x20 || r > 0x7e {
+				return fmt.Errorf("o conteúdo TXT deve conter apenas caracteres ASCII imprimíveis")
+			}
+		}
+	default:
+		return fmt.Errorf("tipo de registro não suportado: %q", p.Type)
+	}
+	return nil
+}
+
+// isValidHostname verifica a sintaxe de um nome de host (RFC 1123).
+// Quando allowUnderscore é verdadeiro, aceita rótulos como "_dmarc".
+func isValidHostname(host string, allowUnderscore bool) bool {
+	if host == "" || len(host) > 253 {
+		return false
+	}
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, r := range label {
+			switch {
+			case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+			case r == '_' && allowUnderscore:
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
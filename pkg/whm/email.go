@@ -0,0 +1,108 @@
+package whm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// uapiResult reflete o resultado de uma função UAPI do cPanel executada pela API
+// 'uapi_cpanel' do WHM, que age em nome da conta informada.
+type uapiResult struct {
+	Status int             `json:"status"`
+	Errors []string        `json:"errors"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// uapi executa a função UAPI module::function como o usuário cPanel informado e
+// decodifica o campo data do resultado em T.
+func uapi[T any](c *Client, username, module, function string, params url.Values) (T, error) {
+	var data T
+
+	params.Set("cpanel.user", username)
+	params.Set("cpanel.module", module)
+	params.Set("cpanel.function", function)
+	envelope, err := request[struct {
+		UAPI uapiResult `json:"uapi"`
+	}](c, "uapi_cpanel", params)
+	if err != nil {
+		return data, err
+	}
+
+	result := envelope.UAPI
+	if result.Status != 1 {
+		reason := strings.Join(result.Errors, "; ")
+		if reason == "" {
+			reason = "falha sem mensagem de erro"
+		}
+		return data, &APIError{Function: module + "::" + function, Reason: reason}
+	}
+	if len(result.Data) > 0 && string(result.Data) != "null" {
+		if err := json.Unmarshal(result.Data, &data); err != nil {
+			return data, fmt.Errorf("falha ao decodificar os dados de %s::%s do cPanel: %w", module, function, err)
+		}
+	}
+	return data, nil
+}
+
+// Mailbox é uma conta de e-mail da hospedagem.
+type Mailbox struct {
+	Email string
+	// Login é a parte antes do @, usada nas chamadas de senha e remoção.
+	Login  string
+	Domain string
+}
+
+// ListMailboxes chama 'Email::list_pops' e retorna as contas de e-mail da conta cPanel.
+// Entradas sem domínio, como a conta de sistema do cPanel, são ignoradas.
+func (c *Client) ListMailboxes(username string) ([]Mailbox, error) {
+	data, err := uapi[[]struct {
+		Email string `json:"email"`
+	}](c, username, "Email", "list_pops", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	mailboxes := make([]Mailbox, 0, len(data))
+	for _, pop := range data {
+		login, domain, ok := strings.Cut(pop.Email, "@")
+		if !ok {
+			continue
+		}
+		mailboxes = append(mailboxes, Mailbox{Email: pop.Email, Login: login, Domain: domain})
+	}
+	return mailboxes, nil
+}
+
+// CreateMailbox chama 'Email::add_pop' para criar a conta de e-mail login@domain.
+// quotaMB igual a 0 cria a caixa sem limite de espaço.
+func (c *Client) CreateMailbox(username, login, domain, password string, quotaMB int) error {
+	params := url.Values{}
+	params.Set("email", login)
+	params.Set("domain", domain)
+	params.Set("password", password)
+	params.Set("quota", fmt.Sprint(quotaMB))
+	params.Set("send_welcome_email", "0")
+	_, err := uapi[json.RawMessage](c, username, "Email", "add_pop", params)
+	return err
+}
+
+// ChangeMailboxPassword chama 'Email::passwd_pop' para definir uma nova senha para a conta de e-mail.
+func (c *Client) ChangeMailboxPassword(username string, mailbox Mailbox, password string) error {
+	params := url.Values{}
+	params.Set("email", mailbox.Login)
+	params.Set("domain", mailbox.Domain)
+	params.Set("password", password)
+	_, err := uapi[json.RawMessage](c, username, "Email", "passwd_pop", params)
+	return err
+}
+
+// DeleteMailbox chama 'Email::delete_pop' para remover a conta de e-mail e suas mensagens.
+func (c *Client) DeleteMailbox(username string, mailbox Mailbox) error {
+	params := url.Values{}
+	params.Set("email", mailbox.Login)
+	params.Set("domain", mailbox.Domain)
+	_, err := uapi[json.RawMessage](c, username, "Email", "delete_pop", params)
+	return err
+}
@@ -0,0 +1,109 @@
+package whm
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// StartAutoSSL chama a API 'start_autossl_check_for_one_user' do WHM para emitir
+// ou renovar os certificados AutoSSL dos domínios da conta.
+func (c *Client) StartAutoSSL(username string) error {
+	query := url.Values{}
+	query.Set("username", username)
+	return c.exec("start_autossl_check_for_one_user", query)
+}
+
+// SSLCertificate é o certificado instalado em um host virtual da conta.
+type SSLCertificate struct {
+	ServerName string
+	Domains    []string
+	Issuer     string
+	NotAfter   time.Time
+	SelfSigned bool
+}
+
+// Expired indica se o certificado já venceu no momento informado.
+func (c SSLCertificate) Expired(now time.Time) bool {
+	return !c.NotAfter.IsZero() && now.After(c.NotAfter)
+}
+
+// fetchSSLVhostsData reflete os dados de 'fetch_ssl_vhosts'.
+type fetchSSLVhostsData struct {
+	Vhosts []struct {
+		ServerName string `json:"servername"`
+		User       string `json:"user"`
+		Crt        struct {
+			Domains      []string `json:"domains"`
+			NotAfter     apiValue `json:"not_after"` // Unix timestamp
+			IsSelfSigned apiValue `json:"is_self_signed"`
+			Issuer       struct {
+				OrganizationName string `json:"organizationName"`
+				CommonName       string `json:"commonName"`
+			} `json:"issuer"`
+		} `json:"crt"`
+	} `json:"vhosts"`
+}
+
+// AccountCertificates chama a API 'fetch_ssl_vhosts' do WHM e retorna os certificados
+// instalados nos hosts virtuais da conta.
+func (c *Client) AccountCertificates(username string) ([]SSLCertificate, error) {
+	data, err := request[fetchSSLVhostsData](c, "fetch_ssl_vhosts", url.Values{})
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []SSLCertificate
+	for _, vhost := range data.Vhosts {
+		if vhost.User != username {
+			continue
+		}
+		cert := SSLCertificate{
+			ServerName: vhost.ServerName,
+			Domains:    vhost.Crt.Domains,
+			Issuer:     vhost.Crt.Issuer.OrganizationName,
+			SelfSigned: vhost.Crt.IsSelfSigned == "1",
+		}
+		if cert.Issuer == "" {
+			cert.Issuer = vhost.Crt.Issuer.CommonName
+		}
+		if notAfter, err := strconv.ParseInt(string(vhost.Crt.NotAfter), 10, 64); err == nil {
+			cert.NotAfter = time.Unix(notAfter, 0)
+		}
+		certs = append(certs, cert)
+	}
+	sort.Slice(certs, func(i, j int) bool { return certs[i].ServerName < certs[j].ServerName })
+	return certs, nil
+}
+
+// AutoSSLProblem é um domínio da conta que o AutoSSL não conseguiu proteger.
+type AutoSSLProblem struct {
+	Domain  string
+	Problem string
+}
+
+// autoSSLProblemsData reflete os dados de 'get_autossl_problems_for_user'.
+type autoSSLProblemsData struct {
+	Problems []struct {
+		Domain  string `json:"domain"`
+		Problem string `json:"problem"`
+	} `json:"problems"`
+}
+
+// AutoSSLProblems chama a API 'get_autossl_problems_for_user' do WHM e retorna os
+// problemas encontrados na última verificação AutoSSL da conta.
+func (c *Client) AutoSSLProblems(username string) ([]AutoSSLProblem, error) {
+	query := url.Values{}
+	query.Set("username", username)
+
+	data, err := request[autoSSLProblemsData](c, "get_autossl_problems_for_user", query)
+	if err != nil {
+		return nil, err
+	}
+	problems := make([]AutoSSLProblem, 0, len(data.Problems))
+	for _, p := range data.Problems {
+		problems = append(problems, AutoSSLProblem{Domain: p.Domain, Problem: p.Problem})
+	}
+	return problems, nil
+}
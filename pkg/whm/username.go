@@ -0,0 +1,116 @@
+package whm
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// maxUsernameLength mantém os nomes de usuário em 8 caracteres, o trecho que o
+	// cPanel usa como prefixo dos bancos de dados e que precisa ser único.
+	maxUsernameLength = 8
+	// maxUsernameSuffix é o maior sufixo numérico tentado antes de desistir.
+	maxUsernameSuffix = 99
+)
+
+// reservedUsernames são nomes que o cPanel recusa ou que colidem com usuários
+// e serviços do sistema.
+var reservedUsernames = map[string]bool{
+	"admin": true, "all": true, "apache": true, "bin": true, "cpanel": true,
+	"cphulkd": true, "daemon": true, "dovecot": true, "exim": true, "ftp": true,
+	"games": true, "mail": true, "mailman": true, "mysql": true, "named": true,
+	"nobody": true, "operator": true, "postgres": true, "root": true, "shadow": true,
+	"sshd": true, "system": true, "tomcat": true, "toor": true, "user": true,
+	"virtfs": true, "webmail": true, "whm": true, "www": true,
+}
+
+// GenerateUsername gera um nome de usuário cPanel válido e disponível para o
+// domínio. Os candidatos são sempre os mesmos para um domínio: a base e, em caso
+// de colisão, a base com os sufixos 1, 2, 3...
+func (c *Client) GenerateUsername(domain string) (string, error) {
+	base := usernameBase(domain)
+
+	for suffix := 0; suffix <= maxUsernameSuffix; suffix++ {
+		candidate := usernameCandidate(base, suffix)
+		if reservedUsernames[candidate] {
+			continue
+		}
+
+		available, err := c.UsernameAvailable(candidate)
+		if err != nil {
+			return "", err
+		}
+		if available {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("nenhum nome de usuário disponível para o domínio %s", domain)
+}
+
+// UsernameAvailable consulta o WHM para saber se o nome de usuário pode ser usado
+// em uma nova conta. Em servidores sem verify_new_username, verifica apenas se já
+// existe uma conta com esse nome via accountsummary.
+func (c *Client) UsernameAvailable(username string) (bool, error) {
+	query := url.Values{}
+	query.Set("user", username)
+
+	env, err := c.call("verify_new_username", query)
+	if err != nil {
+		return false, err
+	}
+	if env.Metadata.Result == 1 {
+		return true, nil
+	}
+	if !strings.Contains(strings.ToLower(env.Metadata.Reason), "unknown") {
+		return false, nil
+	}
+
+	exists, err := c.AccountExists(username)
+	return !exists, err
+}
+
+// usernameBase converte o domínio em uma base de nome de usuário aceita pelo
+// cPanel: apenas letras minúsculas e dígitos, começando por letra e sem o
+// prefixo "test", que o cPanel reserva.
+func usernameBase(domain string) string {
+	label := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "www.")
+	if i := strings.Index(label, "."); i >= 0 {
+		label = label[:i]
+	}
+
+	var b strings.Builder
+	for _, r := range norm.NFD.String(label) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case unicode.Is(unicode.Mn, r):
+			// Descarta os acentos separados pela normalização (ex.: "ç" vira "c").
+		}
+	}
+	base := b.String()
+
+	if base == "" || base[0] < 'a' || base[0] > 'z' || strings.HasPrefix(base, "test") {
+		base = "d" + base
+	}
+	if len(base) > maxUsernameLength {
+		base = base[:maxUsernameLength]
+	}
+	return base
+}
+
+// usernameCandidate retorna a base com o sufixo numérico, truncando a base para
+// respeitar o tamanho máximo. O sufixo 0 retorna a própria base.
+func usernameCandidate(base string, suffix int) string {
+	if suffix == 0 {
+		return base
+	}
+	s := fmt.Sprint(suffix)
+	if len(base)+len(s) > maxUsernameLength {
+		base = base[:maxUsernameLength-len(s)]
+	}
+	return base + s
+}